@@ -0,0 +1,31 @@
+package paperminer
+
+import "errors"
+
+// ErrPermanent marks errors which are not resolved by retrying, e.g. when
+// a service rejects a document. Documents failing with a permanent error are
+// marked as failed without further retries. Use [Permanent] to mark an
+// error.
+var ErrPermanent = errors.New("permanent error")
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() []error {
+	return []error{e.err, ErrPermanent}
+}
+
+// Permanent marks an error as permanent (see [ErrPermanent]). The error
+// message is not modified. Nil is returned as-is.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err}
+}
@@ -0,0 +1,40 @@
+package paperminer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"go.uber.org/multierr"
+)
+
+func TestPermanent(t *testing.T) {
+	if err := Permanent(nil); err != nil {
+		t.Errorf("Permanent(nil) returned %v", err)
+	}
+
+	err := Permanent(io.ErrUnexpectedEOF)
+
+	if got, want := err.Error(), io.ErrUnexpectedEOF.Error(); got != want {
+		t.Errorf("Error() returned %q, want %q", got, want)
+	}
+
+	for _, i := range []error{
+		err,
+		fmt.Errorf("wrapped: %w", err),
+		multierr.Combine(io.EOF, err),
+	} {
+		if !errors.Is(i, ErrPermanent) {
+			t.Errorf("Error %q is not permanent", i)
+		}
+
+		if !errors.Is(i, io.ErrUnexpectedEOF) {
+			t.Errorf("Error %q doesn't wrap the original error", i)
+		}
+	}
+
+	if errors.Is(io.EOF, ErrPermanent) {
+		t.Errorf("Unmarked error is permanent")
+	}
+}
@@ -16,10 +16,11 @@ import (
 var sketchTextproto string
 
 var Plugin = sketchfacts.MustNew(sketchfacts.Options{
-	Name:      "invoice",
-	Textproto: sketchTextproto,
-	Required:  []string{"correspondent", "bill_total"},
-	Build:     build,
+	Name:       "invoice",
+	Textproto:  sketchTextproto,
+	Required:   []string{"correspondent", "bill_total"},
+	BuildNodes: []string{"bill_total"},
+	Build:      build,
 })
 
 func build(report *sketch.PageReport) (*paperminer.Facts, error) {
@@ -5,9 +5,67 @@ import (
 	"time"
 )
 
+// MonetaryValue is an amount of money in a particular currency.
+type MonetaryValue struct {
+	// ISO 4217 currency code, e.g. "EUR". May be empty to use the default
+	// currency configured in Paperless.
+	Currency string `json:"currency,omitempty"`
+
+	// Amount in the main currency unit. Paperless stores two decimal places.
+	Amount float64 `json:"amount"`
+}
+
+// CustomFieldValue is the value of a Paperless custom field. At most one
+// member may be set. A value without any member set removes the custom field
+// from the document.
+type CustomFieldValue struct {
+	String       *string        `json:"string,omitempty"`
+	Integer      *int64         `json:"integer,omitempty"`
+	Monetary     *MonetaryValue `json:"monetary,omitempty"`
+	Date         *time.Time     `json:"date,omitempty"`
+	Boolean      *bool          `json:"boolean,omitempty"`
+	URL          *string        `json:"url,omitempty"`
+	DocumentLink []int64        `json:"document_link,omitempty"`
+}
+
+// IsZero returns whether no member is set.
+func (v CustomFieldValue) IsZero() bool {
+	return (v.String == nil &&
+		v.Integer == nil &&
+		v.Monetary == nil &&
+		v.Date == nil &&
+		v.Boolean == nil &&
+		v.URL == nil &&
+		v.DocumentLink == nil)
+}
+
+// Names of document fields as used in [Facts.Force].
+const (
+	FieldTitle         = "title"
+	FieldCreated       = "created"
+	FieldDocumentType  = "document_type"
+	FieldCorrespondent = "correspondent"
+	FieldStoragePath   = "storage_path"
+)
+
 type Facts struct {
 	Reporter *string `json:"reporter"`
 
+	// Relative weight used when facts from multiple reporters are merged.
+	// Conflicting values are resolved in favour of the facts with the higher
+	// priority.
+	Priority int `json:"priority,omitempty"`
+
+	// Propose the facts for review instead of applying them right away. Merged
+	// facts are suggested if any contributing facts are.
+	Suggest bool `json:"suggest,omitempty"`
+
+	// Names of fields to set even if they are protected from being
+	// overwritten, e.g. because a user changed them (see [FieldTitle] and
+	// others). Merged facts force the fields forced by any contributing
+	// facts.
+	Force []string `json:"force,omitempty"`
+
 	Title         *string    `json:"title,omitempty"`
 	Created       *time.Time `json:"created,omitempty"`
 	DocumentType  *string    `json:"document_type,omitempty"`
@@ -17,7 +75,9 @@ type Facts struct {
 	SetTags   []string `json:"set_tags,omitempty"`
 	UnsetTags []string `json:"unset_tags,omitempty"`
 
-	// TODO: Support custom fields
+	// Custom field values keyed by the name of the custom field. Custom
+	// fields must already exist in Paperless.
+	CustomFields map[string]CustomFieldValue `json:"custom_fields,omitempty"`
 }
 
 func (f *Facts) String() string {
@@ -37,5 +97,6 @@ func (f *Facts) IsEmpty() bool {
 		f.StoragePath == nil &&
 		f.Created == nil &&
 		len(f.SetTags) == 0 &&
-		len(f.UnsetTags) == 0)
+		len(f.UnsetTags) == 0 &&
+		len(f.CustomFields) == 0)
 }
@@ -8,6 +8,33 @@ import (
 	"github.com/hansmi/paperminer/internal/ref"
 )
 
+func TestCustomFieldValueIsZero(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		value CustomFieldValue
+		want  bool
+	}{
+		{
+			name: "zero",
+			want: true,
+		},
+		{
+			name:  "string",
+			value: CustomFieldValue{String: ref.Ref("")},
+		},
+		{
+			name:  "empty document link",
+			value: CustomFieldValue{DocumentLink: []int64{}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.value.IsZero()); diff != "" {
+				t.Errorf("IsZero() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestFactsIsEmpty(t *testing.T) {
 	for _, tc := range []struct {
 		name  string
@@ -31,6 +58,14 @@ func TestFactsIsEmpty(t *testing.T) {
 			name:  "set tags",
 			value: &Facts{SetTags: []string{"x"}},
 		},
+		{
+			name: "custom fields",
+			value: &Facts{
+				CustomFields: map[string]CustomFieldValue{
+					"total": {Monetary: &MonetaryValue{Currency: "EUR", Amount: 12.3}},
+				},
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			if got := tc.value.String(); got == "" {
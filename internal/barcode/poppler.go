@@ -0,0 +1,92 @@
+package barcode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CheckPoppler returns an error if the pdftoppm program used by
+// [RenderPoppler] can't be found.
+func CheckPoppler() error {
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		return fmt.Errorf("rendering pages requires pdftoppm from Poppler: %w", err)
+	}
+
+	return nil
+}
+
+// RenderPoppler renders pages using the pdftoppm program from Poppler, the
+// PDF library also used by dossier.
+func RenderPoppler(ctx context.Context, path string, opts RenderOptions, fn PageFunc) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "paperminer-pages*")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	args := []string{"-r", strconv.Itoa(opts.DPI), "-gray", "-png"}
+
+	if opts.MaxPages > 0 {
+		args = append(args, "-l", strconv.Itoa(opts.MaxPages))
+	}
+
+	args = append(args, path, filepath.Join(dir, "page"))
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "pdftoppm", args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pdftoppm: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Output files are named "page-N.png", with the page number padded to
+	// the same width, and returned in order.
+	files, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		page, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), "page-"), ".png"))
+		if err != nil {
+			return fmt.Errorf("unexpected output file %q", name)
+		}
+
+		if err := renderFile(name, page, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func renderFile(name string, page int, fn PageFunc) error {
+	fh, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	img, err := png.Decode(fh)
+	if err != nil {
+		return fmt.Errorf("page %d: %w", page, err)
+	}
+
+	return fn(page, img)
+}
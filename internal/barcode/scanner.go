@@ -0,0 +1,134 @@
+// Package barcode decodes barcodes and QR codes on the pages of PDF documents.
+package barcode
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/hansmi/paperminer"
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/multi/qrcode"
+	"github.com/makiuchi-d/gozxing/oned"
+)
+
+const defaultDPI = 200
+
+// PageFunc is invoked for every rendered page. Pages are numbered starting
+// at 1.
+type PageFunc func(page int, img image.Image) error
+
+// RenderOptions control how pages are rendered.
+type RenderOptions struct {
+	// Resolution in dots per inch.
+	DPI int
+
+	// Maximum number of pages to render, starting with the first. Zero for
+	// all pages.
+	MaxPages int
+}
+
+// RenderFunc renders the pages of a PDF document as images, invoking a
+// function for each page in order.
+type RenderFunc func(ctx context.Context, path string, opts RenderOptions, fn PageFunc) error
+
+type Options struct {
+	RenderOptions
+
+	// Function for rendering pages. Defaults to [RenderPoppler].
+	Render RenderFunc
+}
+
+// Scanner finds barcodes on document pages.
+type Scanner struct {
+	opts Options
+}
+
+func NewScanner(opts Options) *Scanner {
+	if opts.DPI <= 0 {
+		opts.DPI = defaultDPI
+	}
+
+	if opts.Render == nil {
+		opts.Render = RenderPoppler
+	}
+
+	return &Scanner{
+		opts: opts,
+	}
+}
+
+// Scan renders the pages of a PDF document and decodes all barcodes found on
+// them.
+func (s *Scanner) Scan(ctx context.Context, path string) ([]paperminer.Barcode, error) {
+	var result []paperminer.Barcode
+
+	if err := s.opts.Render(ctx, path, s.opts.RenderOptions, func(page int, img image.Image) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for _, b := range Decode(img) {
+			b.Page = page
+			result = append(result, b)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("rendering pages: %w", err)
+	}
+
+	return result, nil
+}
+
+func oneDReaders(hints map[gozxing.DecodeHintType]any) []gozxing.Reader {
+	return []gozxing.Reader{
+		oned.NewMultiFormatUPCEANReader(hints),
+		oned.NewCode128Reader(),
+		oned.NewCode39Reader(),
+		oned.NewCode93Reader(),
+		oned.NewITFReader(),
+	}
+}
+
+// Decode returns all QR codes and at most one barcode per 1D symbology found
+// in an image. The page number of the returned barcodes is not set.
+func Decode(img image.Image) []paperminer.Barcode {
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil
+	}
+
+	hints := map[gozxing.DecodeHintType]any{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+
+	// Decoding failures only mean that no barcode was found.
+	results, _ := qrcode.NewQRCodeMultiReader().DecodeMultiple(bmp, hints)
+
+	for _, r := range oneDReaders(hints) {
+		if res, err := r.Decode(bmp, hints); err == nil {
+			results = append(results, res)
+		}
+	}
+
+	var barcodes []paperminer.Barcode
+
+	seen := map[paperminer.Barcode]struct{}{}
+
+	for _, res := range results {
+		b := paperminer.Barcode{
+			Format: res.GetBarcodeFormat().String(),
+			Text:   res.GetText(),
+		}
+
+		if _, ok := seen[b]; ok {
+			continue
+		}
+
+		seen[b] = struct{}{}
+		barcodes = append(barcodes, b)
+	}
+
+	return barcodes
+}
@@ -0,0 +1,177 @@
+package barcode
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/oned"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+type testCode struct {
+	format gozxing.BarcodeFormat
+	text   string
+}
+
+// testPage returns a white page with the given codes drawn below each other.
+func testPage(t *testing.T, codes ...testCode) image.Image {
+	t.Helper()
+
+	page := image.NewGray(image.Rect(0, 0, 800, 400*max(1, len(codes))))
+	draw.Draw(page, page.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for idx, c := range codes {
+		var w gozxing.Writer
+
+		switch c.format {
+		case gozxing.BarcodeFormat_QR_CODE:
+			w = qrcode.NewQRCodeWriter()
+		case gozxing.BarcodeFormat_CODE_128:
+			w = oned.NewCode128Writer()
+		default:
+			t.Fatalf("Unsupported format %v", c.format)
+		}
+
+		m, err := w.Encode(c.text, c.format, 300, 300, nil)
+		if err != nil {
+			t.Fatalf("Encode(%q) failed: %v", c.text, err)
+		}
+
+		offset := image.Pt(50, 50+idx*400)
+
+		draw.Draw(page, m.Bounds().Add(offset), m, image.Point{}, draw.Src)
+	}
+
+	return page
+}
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		codes []testCode
+		want  []paperminer.Barcode
+	}{
+		{name: "empty"},
+		{
+			name: "QR code",
+			codes: []testCode{
+				{gozxing.BarcodeFormat_QR_CODE, "BCD\n002\n1\nSCT\n\nRed Cross\nBE72000000001616"},
+			},
+			want: []paperminer.Barcode{
+				{Format: "QR_CODE", Text: "BCD\n002\n1\nSCT\n\nRed Cross\nBE72000000001616"},
+			},
+		},
+		{
+			name: "Code 128",
+			codes: []testCode{
+				{gozxing.BarcodeFormat_CODE_128, "REF-2024-0042"},
+			},
+			want: []paperminer.Barcode{
+				{Format: "CODE_128", Text: "REF-2024-0042"},
+			},
+		},
+		{
+			name: "multiple",
+			codes: []testCode{
+				{gozxing.BarcodeFormat_QR_CODE, "first"},
+				{gozxing.BarcodeFormat_QR_CODE, "second"},
+				{gozxing.BarcodeFormat_CODE_128, "12345678"},
+			},
+			want: []paperminer.Barcode{
+				{Format: "QR_CODE", Text: "first"},
+				{Format: "QR_CODE", Text: "second"},
+				{Format: "CODE_128", Text: "12345678"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Decode(testPage(t, tc.codes...))
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b paperminer.Barcode) bool {
+				return a.Text < b.Text
+			})); diff != "" {
+				t.Errorf("Decode() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	errTest := errors.New("test")
+
+	pages := []image.Image{
+		testPage(t),
+		testPage(t, testCode{gozxing.BarcodeFormat_QR_CODE, "payload"}),
+	}
+
+	for _, tc := range []struct {
+		name      string
+		maxPages  int
+		renderErr error
+		want      []paperminer.Barcode
+		wantErr   error
+	}{
+		{
+			name: "all pages",
+			want: []paperminer.Barcode{
+				{Format: "QR_CODE", Page: 2, Text: "payload"},
+			},
+		},
+		{
+			name:     "first page",
+			maxPages: 1,
+		},
+		{
+			name:      "render error",
+			renderErr: errTest,
+			wantErr:   errTest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewScanner(Options{
+				RenderOptions: RenderOptions{
+					MaxPages: tc.maxPages,
+				},
+				Render: func(ctx context.Context, path string, opts RenderOptions, fn PageFunc) error {
+					if opts.DPI != defaultDPI {
+						t.Errorf("Rendering with %d DPI, want %d", opts.DPI, defaultDPI)
+					}
+
+					if tc.renderErr != nil {
+						return tc.renderErr
+					}
+
+					for idx, img := range pages {
+						if opts.MaxPages > 0 && idx >= opts.MaxPages {
+							break
+						}
+
+						if err := fn(idx+1, img); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				},
+			})
+
+			got, err := s.Scan(context.Background(), "doc.pdf")
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Scan() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package barcodefacter implements a built-in facter reporting facts from
+// payment codes (Swiss QR-bill, EPC QR code) found on document pages.
+package barcodefacter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"github.com/hansmi/paperminer/pkg/paymentcode"
+	"github.com/hansmi/staticplug"
+	"go.uber.org/zap"
+)
+
+// Name of the built-in facter.
+const Name = "barcodes"
+
+type Options struct {
+	// Name of a monetary custom field for the requested amount. The amount
+	// isn't reported if empty.
+	AmountField string
+
+	// Name of a string custom field for the payment reference. The reference
+	// isn't reported if empty.
+	ReferenceField string
+}
+
+type Facter struct {
+	opts Options
+}
+
+var _ staticplug.Plugin = (*Facter)(nil)
+var _ paperminer.DocumentFacter = (*Facter)(nil)
+
+// New creates a facter using the barcodes decoded from a document (see
+// [paperminer.DocumentFacterOptions.Barcodes]).
+func New(opts Options) *Facter {
+	return &Facter{
+		opts: opts,
+	}
+}
+
+func (f *Facter) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: Name,
+		New: func() (staticplug.Plugin, error) {
+			// Instances are stateless.
+			return f, nil
+		},
+	}
+}
+
+func (f *Facter) facts(p *paymentcode.Payment) *paperminer.Facts {
+	facts := &paperminer.Facts{}
+
+	if p.Creditor != "" {
+		facts.Correspondent = ref.Ref(p.Creditor)
+	}
+
+	setField := func(name string, value paperminer.CustomFieldValue) {
+		if name == "" {
+			return
+		}
+
+		if facts.CustomFields == nil {
+			facts.CustomFields = map[string]paperminer.CustomFieldValue{}
+		}
+
+		facts.CustomFields[name] = value
+	}
+
+	if p.Amount != nil {
+		setField(f.opts.AmountField, paperminer.CustomFieldValue{Monetary: ref.Ref(*p.Amount)})
+	}
+
+	if p.Reference != "" {
+		setField(f.opts.ReferenceField, paperminer.CustomFieldValue{String: ref.Ref(p.Reference)})
+	}
+
+	return facts
+}
+
+// DocumentFacts reports facts from the first valid payment code. Other
+// barcodes are ignored.
+func (f *Facter) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	for _, b := range opts.Barcodes {
+		p, err := paymentcode.Parse(b.Text)
+		if errors.Is(err, paymentcode.ErrUnknownFormat) {
+			continue
+		} else if err != nil {
+			opts.Logger.Warn("Invalid payment code",
+				zap.Int("page", b.Page),
+				zap.String("format", b.Format),
+				zap.Error(err))
+			continue
+		}
+
+		return f.facts(p), nil
+	}
+
+	return nil, nil
+}
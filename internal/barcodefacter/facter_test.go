@@ -0,0 +1,80 @@
+package barcodefacter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"go.uber.org/zap/zaptest"
+)
+
+const testEPC = "BCD\n002\n1\nSCT\nBHBLDEHHXXX\nFranz Mustermann\nDE71110220330123456789\nEUR12.3\n\nRF18539007547034"
+
+func TestDocumentFacts(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     Options
+		barcodes []paperminer.Barcode
+		want     *paperminer.Facts
+	}{
+		{name: "no barcodes"},
+		{
+			name: "other barcodes",
+			barcodes: []paperminer.Barcode{
+				{Format: "CODE_128", Page: 1, Text: "12345"},
+				{Format: "QR_CODE", Page: 1, Text: "https://example.com/"},
+			},
+		},
+		{
+			name: "invalid payment code",
+			barcodes: []paperminer.Barcode{
+				{Format: "QR_CODE", Page: 1, Text: "BCD\n003\n"},
+			},
+		},
+		{
+			name: "correspondent only",
+			barcodes: []paperminer.Barcode{
+				{Format: "QR_CODE", Page: 2, Text: testEPC},
+			},
+			want: &paperminer.Facts{
+				Correspondent: ref.Ref("Franz Mustermann"),
+			},
+		},
+		{
+			name: "custom fields",
+			opts: Options{
+				AmountField:    "Amount",
+				ReferenceField: "Reference",
+			},
+			barcodes: []paperminer.Barcode{
+				{Format: "QR_CODE", Page: 1, Text: "BCD\n002\n1\nSCT\n"},
+				{Format: "QR_CODE", Page: 2, Text: testEPC},
+				{Format: "QR_CODE", Page: 3, Text: "BCD\n002\n1\nSCT\n\nOther\nBE72000000001616"},
+			},
+			want: &paperminer.Facts{
+				Correspondent: ref.Ref("Franz Mustermann"),
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					"Amount":    {Monetary: &paperminer.MonetaryValue{Currency: "EUR", Amount: 12.3}},
+					"Reference": {String: ref.Ref("RF18539007547034")},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := New(tc.opts).DocumentFacts(context.Background(), paperminer.DocumentFacterOptions{
+				Logger:   zaptest.NewLogger(t),
+				Info:     &paperminer.DocumentInfo{},
+				Barcodes: tc.barcodes,
+			})
+			if err != nil {
+				t.Errorf("DocumentFacts() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Facts diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
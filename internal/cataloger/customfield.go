@@ -0,0 +1,97 @@
+package cataloger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+)
+
+// customFieldJSONValue converts a custom field value into the representation
+// used by the Paperless API. Zero values are returned as nil.
+func customFieldJSONValue(v paperminer.CustomFieldValue) (any, error) {
+	var result []any
+
+	if v.String != nil {
+		result = append(result, *v.String)
+	}
+
+	if v.Integer != nil {
+		result = append(result, *v.Integer)
+	}
+
+	if m := v.Monetary; m != nil {
+		result = append(result, fmt.Sprintf("%s%.2f", strings.ToUpper(m.Currency), m.Amount))
+	}
+
+	if v.Date != nil {
+		result = append(result, v.Date.Format("2006-01-02"))
+	}
+
+	if v.Boolean != nil {
+		result = append(result, *v.Boolean)
+	}
+
+	if v.URL != nil {
+		result = append(result, *v.URL)
+	}
+
+	if v.DocumentLink != nil {
+		result = append(result, normalizeIDs(v.DocumentLink))
+	}
+
+	switch len(result) {
+	case 0:
+		return nil, nil
+	case 1:
+		return result[0], nil
+	}
+
+	return nil, fmt.Errorf("%w: custom field value has %d members set", os.ErrInvalid, len(result))
+}
+
+// checkCustomFieldType verifies that the member set in a value is accepted by
+// a custom field data type. Integers are accepted for float fields. Unknown
+// data types are not checked.
+func checkCustomFieldType(v paperminer.CustomFieldValue, dataType plclient.CustomFieldDataType) error {
+	var ok bool
+
+	switch dataType {
+	case plclient.CustomFieldString:
+		ok = v.String != nil
+	case plclient.CustomFieldURL:
+		ok = v.URL != nil
+	case plclient.CustomFieldDate:
+		ok = v.Date != nil
+	case plclient.CustomFieldBoolean:
+		ok = v.Boolean != nil
+	case plclient.CustomFieldInteger, plclient.CustomFieldFloat:
+		ok = v.Integer != nil
+	case plclient.CustomFieldMonetary:
+		ok = v.Monetary != nil
+	case plclient.CustomFieldDocumentLink:
+		ok = v.DocumentLink != nil
+	default:
+		return nil
+	}
+
+	if !(ok || v.IsZero()) {
+		return fmt.Errorf("%w: value doesn't match data type %q", os.ErrInvalid, dataType)
+	}
+
+	return nil
+}
+
+// jsonEqual compares two values by their JSON representation. Values decoded
+// from API responses use different types than those built locally (e.g.
+// float64 instead of int64).
+func jsonEqual(a, b any) bool {
+	abuf, aerr := json.Marshal(a)
+	bbuf, berr := json.Marshal(b)
+
+	return aerr == nil && berr == nil && bytes.Equal(abuf, bbuf)
+}
@@ -0,0 +1,136 @@
+package cataloger
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+)
+
+func TestCustomFieldJSONValue(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		value   paperminer.CustomFieldValue
+		want    any
+		wantErr error
+	}{
+		{name: "zero"},
+		{
+			name:  "string",
+			value: paperminer.CustomFieldValue{String: ref.Ref("text")},
+			want:  "text",
+		},
+		{
+			name:  "integer",
+			value: paperminer.CustomFieldValue{Integer: ref.Ref[int64](-123)},
+			want:  int64(-123),
+		},
+		{
+			name: "monetary",
+			value: paperminer.CustomFieldValue{
+				Monetary: &paperminer.MonetaryValue{Currency: "chf", Amount: 1234.567},
+			},
+			want: "CHF1234.57",
+		},
+		{
+			name: "monetary without currency",
+			value: paperminer.CustomFieldValue{
+				Monetary: &paperminer.MonetaryValue{Amount: 3},
+			},
+			want: "3.00",
+		},
+		{
+			name:  "date",
+			value: paperminer.CustomFieldValue{Date: ref.Ref(time.Date(2021, time.December, 31, 23, 0, 0, 0, time.UTC))},
+			want:  "2021-12-31",
+		},
+		{
+			name:  "boolean",
+			value: paperminer.CustomFieldValue{Boolean: ref.Ref(false)},
+			want:  false,
+		},
+		{
+			name:  "url",
+			value: paperminer.CustomFieldValue{URL: ref.Ref("https://example.com/")},
+			want:  "https://example.com/",
+		},
+		{
+			name:  "document link",
+			value: paperminer.CustomFieldValue{DocumentLink: []int64{3, 1, 3}},
+			want:  []int64{1, 3},
+		},
+		{
+			name: "multiple",
+			value: paperminer.CustomFieldValue{
+				String:  ref.Ref("text"),
+				Integer: ref.Ref[int64](1),
+			},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := customFieldJSONValue(tc.value)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Value diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckCustomFieldType(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		value    paperminer.CustomFieldValue
+		dataType plclient.CustomFieldDataType
+		wantErr  error
+	}{
+		{
+			name:     "string",
+			value:    paperminer.CustomFieldValue{String: ref.Ref("text")},
+			dataType: plclient.CustomFieldString,
+		},
+		{
+			name:     "integer as float",
+			value:    paperminer.CustomFieldValue{Integer: ref.Ref[int64](1)},
+			dataType: plclient.CustomFieldFloat,
+		},
+		{
+			name:     "unset",
+			dataType: plclient.CustomFieldMonetary,
+		},
+		{
+			name:  "unknown data type",
+			value: paperminer.CustomFieldValue{String: ref.Ref("text")},
+		},
+		{
+			name:     "monetary as string",
+			value:    paperminer.CustomFieldValue{Monetary: &paperminer.MonetaryValue{Currency: "EUR", Amount: 1}},
+			dataType: plclient.CustomFieldString,
+			wantErr:  os.ErrInvalid,
+		},
+		{
+			name:     "string as url",
+			value:    paperminer.CustomFieldValue{String: ref.Ref("https://example.com/")},
+			dataType: plclient.CustomFieldURL,
+			wantErr:  os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkCustomFieldType(tc.value, tc.dataType)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
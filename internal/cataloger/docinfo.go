@@ -0,0 +1,74 @@
+package cataloger
+
+import (
+	"context"
+
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/objectresolver"
+	"go.uber.org/zap"
+)
+
+// newDocumentInfo builds the read-only document description given to facters.
+// Assigned objects are resolved to their names. Objects which can't be
+// resolved, e.g. due to missing permissions, are logged and left empty.
+func newDocumentInfo(ctx context.Context, logger *zap.Logger, resolvers *objectresolver.ObjectResolvers, doc *plclient.Document, metadata *plclient.DocumentMetadata) *paperminer.DocumentInfo {
+	info := &paperminer.DocumentInfo{
+		ID:                  doc.ID,
+		Title:               doc.Title,
+		Created:             doc.Created,
+		Modified:            doc.Modified,
+		Added:               doc.Added,
+		ArchiveSerialNumber: doc.ArchiveSerialNumber,
+		OriginalFileName:    doc.OriginalFileName,
+	}
+
+	if metadata != nil {
+		info.OriginalMimeType = metadata.OriginalMimeType
+		info.Language = metadata.Lang
+	}
+
+	resolve := func(id *int64, get func(context.Context, int64) (string, error)) string {
+		if id == nil {
+			return ""
+		}
+
+		name, err := get(ctx, *id)
+		if err != nil {
+			logger.Warn("Resolving object for document info failed", zap.Error(err))
+		}
+
+		return name
+	}
+
+	for _, id := range doc.Tags {
+		if name := resolve(&id, func(ctx context.Context, id int64) (string, error) {
+			obj, err := resolvers.Tag.GetByID(ctx, id)
+			return obj.Name, err
+		}); name != "" {
+			info.Tags = append(info.Tags, name)
+		}
+	}
+
+	info.Correspondent = resolve(doc.Correspondent, func(ctx context.Context, id int64) (string, error) {
+		obj, err := resolvers.Correspondent.GetByID(ctx, id)
+		return obj.Name, err
+	})
+
+	info.DocumentType = resolve(doc.DocumentType, func(ctx context.Context, id int64) (string, error) {
+		obj, err := resolvers.DocumentType.GetByID(ctx, id)
+		return obj.Name, err
+	})
+
+	info.StoragePath = resolve(doc.StoragePath, func(ctx context.Context, id int64) (string, error) {
+		obj, err := resolvers.StoragePath.GetByID(ctx, id)
+		return obj.Name, err
+	})
+
+	info.Owner = resolve(doc.Owner, func(ctx context.Context, id int64) (string, error) {
+		obj, err := resolvers.User.GetByID(ctx, id)
+		return obj.Username, err
+	})
+
+	return info
+}
@@ -0,0 +1,84 @@
+package cataloger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/objectresolver"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewDocumentInfo(t *testing.T) {
+	resolvers := objectresolver.NewMemObjectResolvers()
+
+	tagA := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "tag a")
+	tagB := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "tag b")
+	correspondent := objectresolver.MustGetOrCreateByName(t, resolvers.Correspondent, "ACME")
+	storagePath := objectresolver.MustGetOrCreateByName(t, resolvers.StoragePath, "invoices")
+	owner := objectresolver.MustGetOrCreateByName(t, resolvers.User, "jdoe")
+
+	created := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name     string
+		doc      plclient.Document
+		metadata *plclient.DocumentMetadata
+		want     *paperminer.DocumentInfo
+	}{
+		{
+			name: "empty",
+			want: &paperminer.DocumentInfo{},
+		},
+		{
+			name: "full",
+			doc: plclient.Document{
+				ID:               123,
+				Title:            "title",
+				Created:          created,
+				OriginalFileName: "scan.pdf",
+				Tags:             []int64{tagA.ID, tagB.ID},
+				Correspondent:    &correspondent.ID,
+				StoragePath:      &storagePath.ID,
+				Owner:            &owner.ID,
+			},
+			metadata: &plclient.DocumentMetadata{
+				OriginalMimeType: "application/pdf",
+				Lang:             "de",
+			},
+			want: &paperminer.DocumentInfo{
+				ID:               123,
+				Title:            "title",
+				Created:          created,
+				OriginalFileName: "scan.pdf",
+				OriginalMimeType: "application/pdf",
+				Language:         "de",
+				Tags:             []string{"tag a", "tag b"},
+				Correspondent:    "ACME",
+				StoragePath:      "invoices",
+				Owner:            "jdoe",
+			},
+		},
+		{
+			name: "unknown objects",
+			doc: plclient.Document{
+				Tags:         []int64{-1, tagB.ID},
+				DocumentType: plclient.Int64(-2),
+			},
+			want: &paperminer.DocumentInfo{
+				Tags: []string{"tag b"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newDocumentInfo(context.Background(), zaptest.NewLogger(t), resolvers, &tc.doc, tc.metadata)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("newDocumentInfo() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package cataloger
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+)
+
+// patchMerger rebases a patch built for the base version of a document onto
+// the current version (three-way merge).
+type patchMerger struct {
+	base *plclient.Document
+	cur  *plclient.Document
+
+	result    *plclient.DocumentFields
+	conflicts []string
+}
+
+// mergeValue applies a value if the field is unchanged since the base
+// version. Concurrent changes to the same value are accepted.
+func mergeValue[T any](m *patchMerger, field string, want, base, cur T,
+	equal func(a, b T) bool,
+	set func(*plclient.DocumentFields, T) *plclient.DocumentFields,
+) {
+	if equal(cur, want) {
+		return
+	}
+
+	if !equal(cur, base) {
+		m.conflicts = append(m.conflicts, field)
+		return
+	}
+
+	m.result = set(m.result, want)
+}
+
+// mergeTags applies the tags added and removed by the patch to the current
+// tags. Tags changed concurrently are retained.
+func (m *patchMerger) mergeTags(want []int64) {
+	result := slices.Clone(m.cur.Tags)
+
+	for _, id := range want {
+		if !slices.Contains(m.base.Tags, id) {
+			result = append(result, id)
+		}
+	}
+
+	result = slices.DeleteFunc(result, func(id int64) bool {
+		return slices.Contains(m.base.Tags, id) && !slices.Contains(want, id)
+	})
+
+	if result = normalizeIDs(result); !slices.Equal(result, normalizeIDs(m.cur.Tags)) {
+		m.result = m.result.SetTags(result)
+	}
+}
+
+func findCustomField(instances []plclient.CustomFieldInstance, id int64) (any, bool) {
+	for _, i := range instances {
+		if i.Field == id {
+			return i.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// mergeCustomFields applies the custom field values added, changed and
+// removed by the patch to the current custom fields. Fields changed
+// concurrently to a different value are conflicts.
+func (m *patchMerger) mergeCustomFields(want []plclient.CustomFieldInstance) {
+	type state struct {
+		value any
+		ok    bool
+	}
+
+	equal := func(a, b state) bool {
+		return a.ok == b.ok && (!a.ok || jsonEqual(a.value, b.value))
+	}
+
+	var ids []int64
+
+	for _, instances := range [][]plclient.CustomFieldInstance{m.base.CustomFields, want} {
+		for _, i := range instances {
+			if !slices.Contains(ids, i.Field) {
+				ids = append(ids, i.Field)
+			}
+		}
+	}
+
+	result := slices.Clone(m.cur.CustomFields)
+	changed := false
+
+	for _, id := range ids {
+		var base, cur, wanted state
+
+		base.value, base.ok = findCustomField(m.base.CustomFields, id)
+		cur.value, cur.ok = findCustomField(m.cur.CustomFields, id)
+		wanted.value, wanted.ok = findCustomField(want, id)
+
+		if equal(base, wanted) || equal(cur, wanted) {
+			continue
+		}
+
+		if !equal(cur, base) {
+			m.conflicts = append(m.conflicts, fmt.Sprintf("custom field %d", id))
+			continue
+		}
+
+		changed = true
+
+		result = slices.DeleteFunc(result, func(i plclient.CustomFieldInstance) bool {
+			return i.Field == id
+		})
+
+		if wanted.ok {
+			result = append(result, plclient.CustomFieldInstance{
+				Field: id,
+				Value: wanted.value,
+			})
+		}
+	}
+
+	if changed {
+		m.result = m.result.SetCustomFields(result)
+	}
+}
+
+func equalInt64Ptr(a, b *int64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// mergePatch rebases a patch built for the base version of a document onto
+// the current version. Fields changed concurrently are retained unless the
+// patch changes them too. An error wrapping errConcurrentModification names
+// the fields with conflicting changes.
+func mergePatch(base, cur *plclient.Document, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
+	m := &patchMerger{
+		base:   base,
+		cur:    cur,
+		result: plclient.NewDocumentFields(),
+	}
+
+	fields := patch.AsMap()
+
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		value := fields[name]
+
+		switch name {
+		case "title":
+			mergeValue(m, name, value.(string), base.Title, cur.Title,
+				func(a, b string) bool { return a == b },
+				(*plclient.DocumentFields).SetTitle)
+
+		case "created":
+			mergeValue(m, name, value.(time.Time), base.Created, cur.Created,
+				time.Time.Equal,
+				(*plclient.DocumentFields).SetCreated)
+
+		case "correspondent":
+			mergeValue(m, name, value.(*int64), base.Correspondent, cur.Correspondent,
+				equalInt64Ptr,
+				(*plclient.DocumentFields).SetCorrespondent)
+
+		case "document_type":
+			mergeValue(m, name, value.(*int64), base.DocumentType, cur.DocumentType,
+				equalInt64Ptr,
+				(*plclient.DocumentFields).SetDocumentType)
+
+		case "storage_path":
+			mergeValue(m, name, value.(*int64), base.StoragePath, cur.StoragePath,
+				equalInt64Ptr,
+				(*plclient.DocumentFields).SetStoragePath)
+
+		case "tags":
+			m.mergeTags(value.([]int64))
+
+		case "custom_fields":
+			m.mergeCustomFields(value.([]plclient.CustomFieldInstance))
+
+		default:
+			return nil, fmt.Errorf("%w: merging field %q is not supported", os.ErrInvalid, name)
+		}
+	}
+
+	if len(m.conflicts) > 0 {
+		return nil, fmt.Errorf("%w: conflicting changes to %s", errConcurrentModification, strings.Join(m.conflicts, ", "))
+	}
+
+	return m.result, nil
+}
@@ -0,0 +1,206 @@
+package cataloger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/document"
+	"golang.org/x/exp/maps"
+)
+
+const failureNoteFingerprintPrefix = "Fingerprint: "
+
+// Absolute file paths in error messages, starting at the beginning of the
+// message, after whitespace or after a quote. Paths of temporary files differ
+// between attempts. Other text containing slashes, e.g. MIME types or URLs,
+// is kept.
+var errorPathRe = regexp.MustCompile(`(^|[\s"'])(?:/|[A-Za-z]:\\)[^\s"']*`)
+
+// failureNote describes a permanent processing failure in a document note.
+type failureNote struct {
+	err      error
+	attempts int
+	facters  []string
+	variants []document.Variant
+}
+
+func (n failureNote) body() string {
+	var variants []string
+
+	for _, v := range n.variants {
+		variants = append(variants, v.String())
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Error: %v\n", n.err)
+	fmt.Fprintf(&sb, "Facters: %s\n", strings.Join(n.facters, ", "))
+	fmt.Fprintf(&sb, "Variants: %s\n", strings.Join(variants, ", "))
+
+	return sb.String()
+}
+
+// fingerprint identifies a failure independent of the number of attempts and
+// of file paths in the error message.
+func (n failureNote) fingerprint() string {
+	var variants []string
+
+	for _, v := range n.variants {
+		variants = append(variants, v.String())
+	}
+
+	h := sha256.New()
+
+	for _, i := range []string{
+		errorPathRe.ReplaceAllString(fmt.Sprint(n.err), "${1}<path>"),
+		strings.Join(n.facters, ", "),
+		strings.Join(variants, ", "),
+	} {
+		fmt.Fprintf(h, "%q\n", i)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func (n failureNote) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("Document processing failed permanently.\n\n")
+	sb.WriteString(n.body())
+	fmt.Fprintf(&sb, "Attempts: %d\n\n", n.attempts)
+	sb.WriteString(failureNoteFingerprintPrefix + n.fingerprint())
+
+	return sb.String()
+}
+
+// isDuplicateOf returns whether an existing note describes the same failure.
+func (n failureNote) isDuplicateOf(note string) bool {
+	return strings.Contains(note, failureNoteFingerprintPrefix+n.fingerprint())
+}
+
+const suggestionNoteHeader = "Suggested changes awaiting review."
+const suggestionNoteFactsPrefix = "Facts: "
+
+// suggestionNote lists facts proposed for review. The facts and the document
+// file checksums are included in JSON format, allowing them to be approved
+// without a store record.
+type suggestionNote struct {
+	facts            *paperminer.Facts
+	originalChecksum string
+	archiveChecksum  string
+	approveTag       string
+}
+
+// suggestionNoteData is the JSON representation of a suggestion.
+type suggestionNoteData struct {
+	Facts            *paperminer.Facts `json:"facts"`
+	OriginalChecksum string            `json:"original_checksum"`
+	ArchiveChecksum  string            `json:"archive_checksum,omitempty"`
+}
+
+func (n suggestionNote) String() string {
+	f := n.facts
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s Add the tag %q to apply them.\n\n", suggestionNoteHeader, n.approveTag)
+
+	for _, i := range []struct {
+		name  string
+		value *string
+	}{
+		{"Title", f.Title},
+		{"Correspondent", f.Correspondent},
+		{"Document type", f.DocumentType},
+		{"Storage path", f.StoragePath},
+	} {
+		if i.value != nil {
+			fmt.Fprintf(&sb, "%s: %q\n", i.name, *i.value)
+		}
+	}
+
+	if f.Created != nil {
+		fmt.Fprintf(&sb, "Created: %s\n", f.Created.Format("2006-01-02"))
+	}
+
+	if len(f.SetTags) > 0 {
+		fmt.Fprintf(&sb, "Set tags: %s\n", strings.Join(f.SetTags, ", "))
+	}
+
+	if len(f.UnsetTags) > 0 {
+		fmt.Fprintf(&sb, "Unset tags: %s\n", strings.Join(f.UnsetTags, ", "))
+	}
+
+	names := maps.Keys(f.CustomFields)
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		value, _ := json.Marshal(f.CustomFields[name])
+
+		fmt.Fprintf(&sb, "Custom field %q: %s\n", name, value)
+	}
+
+	if f.Reporter != nil {
+		fmt.Fprintf(&sb, "Reporter: %s\n", *f.Reporter)
+	}
+
+	buf, err := json.Marshal(suggestionNoteData{
+		Facts:            f,
+		OriginalChecksum: n.originalChecksum,
+		ArchiveChecksum:  n.archiveChecksum,
+	})
+	if err != nil {
+		buf = []byte(err.Error())
+	}
+
+	sb.WriteString("\n" + suggestionNoteFactsPrefix)
+	sb.Write(buf)
+
+	return sb.String()
+}
+
+// isDuplicateOf returns whether an existing suggestion note, if any, proposes
+// the same facts for the same document files.
+func (n suggestionNote) isDuplicateOf(other *suggestionNote) bool {
+	return (other != nil &&
+		n.originalChecksum == other.originalChecksum &&
+		n.archiveChecksum == other.archiveChecksum &&
+		jsonEqual(n.facts, other.facts))
+}
+
+// parseSuggestionNote returns the facts and checksums from a suggestion note.
+// Nil is returned for other notes.
+func parseSuggestionNote(note string) (*suggestionNote, error) {
+	if !strings.HasPrefix(note, suggestionNoteHeader) {
+		return nil, nil
+	}
+
+	idx := strings.LastIndex(note, "\n"+suggestionNoteFactsPrefix)
+	if idx < 0 {
+		return nil, errors.New("suggestion note without facts")
+	}
+
+	var data suggestionNoteData
+
+	if err := json.Unmarshal([]byte(note[idx+1+len(suggestionNoteFactsPrefix):]), &data); err != nil {
+		return nil, fmt.Errorf("suggestion note: %w", err)
+	}
+
+	if data.Facts == nil {
+		return nil, errors.New("suggestion note without facts")
+	}
+
+	return &suggestionNote{
+		facts:            data.Facts,
+		originalChecksum: data.OriginalChecksum,
+		archiveChecksum:  data.ArchiveChecksum,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package cataloger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/document"
+	"github.com/hansmi/paperminer/internal/ref"
+)
+
+func TestFailureNote(t *testing.T) {
+	note := failureNote{
+		err:      errors.New("test error"),
+		attempts: 4,
+		facters:  []string{"first", "second"},
+		variants: []document.Variant{document.Archived, document.Original},
+	}
+
+	got := note.String()
+
+	for _, want := range []string{
+		"Error: test error\n",
+		"Attempts: 4\n",
+		"Facters: first, second\n",
+		"Variants: archived, original\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Note %q doesn't contain %q", got, want)
+		}
+	}
+
+	if !note.isDuplicateOf(got) {
+		t.Errorf("Note is not a duplicate of itself: %q", got)
+	}
+
+	other := note
+	other.attempts = 10
+
+	if !other.isDuplicateOf(got) {
+		t.Errorf("Note with different attempt count is not a duplicate: %q", other.String())
+	}
+
+	other.err = errors.New("another error")
+
+	if other.isDuplicateOf(got) {
+		t.Errorf("Note with different error is a duplicate: %q", other.String())
+	}
+
+	other = note
+	other.facters = []string{"first"}
+
+	if other.isDuplicateOf(got) {
+		t.Errorf("Note with different facters is a duplicate: %q", other.String())
+	}
+}
+
+func TestFailureNoteFingerprintIgnoresPaths(t *testing.T) {
+	errCause := errors.New("parsing failed")
+
+	first := failureNote{
+		err:      fmt.Errorf("variant %q: extracting facts from %q: %w", "original", "/tmp/doc-12-original-1111/2222", errCause),
+		facters:  []string{"first"},
+		variants: []document.Variant{document.Original},
+	}
+
+	second := first
+	second.err = fmt.Errorf("variant %q: extracting facts from %q: %w", "original", "/tmp/doc-12-original-3333/4444", errCause)
+
+	if !second.isDuplicateOf(first.String()) {
+		t.Errorf("Errors differing only by path have different fingerprints: %q, %q", first.fingerprint(), second.fingerprint())
+	}
+
+	third := first
+	third.err = fmt.Errorf("variant %q: extracting facts from %q: %w", "original", "/tmp/doc-12-original-5555/6666", errors.New("other"))
+
+	if third.isDuplicateOf(first.String()) {
+		t.Errorf("Errors with different causes have the same fingerprint: %q", first.fingerprint())
+	}
+}
+
+func TestErrorPathRe(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{input: "/tmp/doc-1/file", want: "<path>"},
+		{input: `open "/tmp/doc-1/file": denied`, want: `open "<path>": denied`},
+		{input: "open /tmp/doc-1/file: no such file", want: "open <path> no such file"},
+		{input: `reading 'C:\Temp\doc': failed`, want: "reading '<path>': failed"},
+		{input: "unsupported format application/pdf", want: "unsupported format application/pdf"},
+		{input: `format "application/json"`, want: `format "application/json"`},
+		{input: "fetching http://a/x failed", want: "fetching http://a/x failed"},
+		{input: "ratio 1/2", want: "ratio 1/2"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := errorPathRe.ReplaceAllString(tc.input, "${1}<path>"); got != tc.want {
+				t.Errorf("ReplaceAllString(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFailureNoteFingerprintKeepsMIMETypesAndURLs(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"unsupported format application/pdf", "unsupported format application/json"},
+		{"fetching http://a/x failed", "fetching http://a/y failed"},
+	} {
+		first := failureNote{err: errors.New(pair[0])}
+		second := failureNote{err: errors.New(pair[1])}
+
+		if second.isDuplicateOf(first.String()) {
+			t.Errorf("Errors %q and %q have the same fingerprint", pair[0], pair[1])
+		}
+	}
+}
+
+func TestSuggestionNote(t *testing.T) {
+	facts := &paperminer.Facts{
+		Reporter:      ref.Ref("first, second"),
+		Suggest:       true,
+		Title:         ref.Ref("Invoice 123"),
+		Created:       ref.Ref(time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)),
+		Correspondent: ref.Ref(""),
+		SetTags:       []string{"invoice", "acme"},
+		CustomFields: map[string]paperminer.CustomFieldValue{
+			"amount": {Monetary: &paperminer.MonetaryValue{Currency: "CHF", Amount: 12.5}},
+		},
+	}
+
+	note := suggestionNote{
+		facts:            facts,
+		originalChecksum: "abc",
+		archiveChecksum:  "def",
+		approveTag:       "approve",
+	}
+
+	got := note.String()
+
+	for _, want := range []string{
+		`Add the tag "approve" to apply them.`,
+		"Title: \"Invoice 123\"\n",
+		"Created: 2024-03-03\n",
+		"Correspondent: \"\"\n",
+		"Set tags: invoice, acme\n",
+		"Custom field \"amount\": {\"monetary\":{\"currency\":\"CHF\",\"amount\":12.5}}\n",
+		"Reporter: first, second\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Note %q doesn't contain %q", got, want)
+		}
+	}
+
+	if parsed, err := parseSuggestionNote(got); err != nil {
+		t.Errorf("parseSuggestionNote() failed: %v", err)
+	} else if diff := cmp.Diff(&note, parsed, cmp.AllowUnexported(suggestionNote{}), cmpopts.IgnoreFields(suggestionNote{}, "approveTag")); diff != "" {
+		t.Errorf("Parsed facts diff (-want +got):\n%s", diff)
+	}
+
+	for _, note := range []string{"", "Other note", failureNote{err: errors.New("test")}.String()} {
+		if parsed, err := parseSuggestionNote(note); err != nil || parsed != nil {
+			t.Errorf("parseSuggestionNote(%q) returned (%v, %v), want nil", note, parsed, err)
+		}
+	}
+
+	for _, note := range []string{
+		suggestionNoteHeader + "\nTitle: x\n",
+		suggestionNoteHeader + "\n\n" + suggestionNoteFactsPrefix + "{",
+		suggestionNoteHeader + "\n\n" + suggestionNoteFactsPrefix + `{"original_checksum":"abc"}`,
+	} {
+		if _, err := parseSuggestionNote(note); err == nil {
+			t.Errorf("parseSuggestionNote(%q) succeeded", note)
+		}
+	}
+}
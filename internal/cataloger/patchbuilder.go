@@ -36,6 +36,16 @@ type patchBuilder struct {
 	storagePath   **int64
 
 	tags map[int64]struct{}
+
+	// Custom field values by field ID. Nil values remove the field.
+	customFields map[int64]any
+
+	// Names of fields kept unless forced by facts (see
+	// [paperminer.Facts.Force]).
+	protected []string
+
+	// Names of protected fields for which facts were ignored.
+	skipped []string
 }
 
 func newPatchBuilder(resolvers *objectresolver.ObjectResolvers, doc *plclient.Document) *patchBuilder {
@@ -43,6 +53,8 @@ func newPatchBuilder(resolvers *objectresolver.ObjectResolvers, doc *plclient.Do
 		resolvers: resolvers,
 		doc:       doc,
 		tags:      map[int64]struct{}{},
+
+		customFields: map[int64]any{},
 	}
 
 	for _, tag := range doc.Tags {
@@ -85,7 +97,37 @@ func setObjectFact[T any](ctx context.Context,
 	return nil
 }
 
+// ignoreFact returns whether a fact must be ignored because the field is protected
+// and the facts don't force it.
+func (b *patchBuilder) ignoreFact(facts *paperminer.Facts, field string, set bool) bool {
+	if !set || !slices.Contains(b.protected, field) || slices.Contains(facts.Force, field) {
+		return false
+	}
+
+	b.skipped = append(b.skipped, field)
+
+	return true
+}
+
 func (b *patchBuilder) setFacts(ctx context.Context, facts *paperminer.Facts) error {
+	facts = ref.Ref(*facts)
+
+	for _, i := range []struct {
+		field string
+		set   bool
+		clear func()
+	}{
+		{paperminer.FieldTitle, facts.Title != nil, func() { facts.Title = nil }},
+		{paperminer.FieldCreated, facts.Created != nil, func() { facts.Created = nil }},
+		{paperminer.FieldDocumentType, facts.DocumentType != nil, func() { facts.DocumentType = nil }},
+		{paperminer.FieldCorrespondent, facts.Correspondent != nil, func() { facts.Correspondent = nil }},
+		{paperminer.FieldStoragePath, facts.StoragePath != nil, func() { facts.StoragePath = nil }},
+	} {
+		if b.ignoreFact(facts, i.field, i.set) {
+			i.clear()
+		}
+	}
+
 	b.created = facts.Created
 	b.title = facts.Title
 
@@ -124,9 +166,79 @@ func (b *patchBuilder) setFacts(ctx context.Context, facts *paperminer.Facts) er
 		}
 	}
 
+	for name, value := range facts.CustomFields {
+		field, err := b.resolvers.CustomField.GetByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("custom field: %w", err)
+		}
+
+		// Paperless rejects mismatching values on every attempt.
+		if err := checkCustomFieldType(value, field.DataType); err != nil {
+			return paperminer.Permanent(fmt.Errorf("custom field %q: %w", name, err))
+		}
+
+		if b.customFields[field.ID], err = customFieldJSONValue(value); err != nil {
+			return fmt.Errorf("custom field %q: %w", name, err)
+		}
+	}
+
 	return nil
 }
 
+// buildCustomFields returns the full list of custom field instances if at
+// least one of them changed. The API replaces all custom fields of
+// a document on update.
+func (b *patchBuilder) buildCustomFields() ([]plclient.CustomFieldInstance, bool) {
+	var changed bool
+
+	result := []plclient.CustomFieldInstance{}
+	seen := map[int64]struct{}{}
+
+	for _, i := range b.doc.CustomFields {
+		seen[i.Field] = struct{}{}
+
+		value, ok := b.customFields[i.Field]
+		if !ok {
+			result = append(result, i)
+			continue
+		}
+
+		if value == nil {
+			changed = true
+			continue
+		}
+
+		if !jsonEqual(value, i.Value) {
+			changed = true
+		}
+
+		result = append(result, plclient.CustomFieldInstance{
+			Field: i.Field,
+			Value: value,
+		})
+	}
+
+	added := maps.Keys(b.customFields)
+
+	slices.Sort(added)
+
+	for _, id := range added {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+
+		if value := b.customFields[id]; value != nil {
+			changed = true
+			result = append(result, plclient.CustomFieldInstance{
+				Field: id,
+				Value: value,
+			})
+		}
+	}
+
+	return result, changed
+}
+
 func patchOptionalValue[T string | *int64](
 	patch *plclient.DocumentFields,
 	set func(*plclient.DocumentFields, T) *plclient.DocumentFields,
@@ -181,5 +293,9 @@ func (b *patchBuilder) build() *plclient.DocumentFields {
 		patch = patch.SetTags(tags)
 	}
 
+	if customFields, changed := b.buildCustomFields(); changed {
+		patch = patch.SetCustomFields(customFields)
+	}
+
 	return patch
 }
@@ -2,7 +2,10 @@ package cataloger
 
 import (
 	"context"
+	"errors"
 	"math"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,12 +28,18 @@ func TestPatchBuilder(t *testing.T) {
 	firstDocumentType := objectresolver.MustGetOrCreateByName(t, resolvers.DocumentType, "first documenttype")
 	firstStoragePath := objectresolver.MustGetOrCreateByName(t, resolvers.StoragePath, "first storagepath")
 
+	totalField := objectresolver.MustGetOrCreateByName(t, resolvers.CustomField, "total")
+	dueField := objectresolver.MustGetOrCreateByName(t, resolvers.CustomField, "due")
+	paidField := objectresolver.MustGetOrCreateByName(t, resolvers.CustomField, "paid")
+
 	for _, tc := range []struct {
 		name         string
 		doc          plclient.Document
+		protected    []string
 		facts        *paperminer.Facts
 		want         map[string]any
 		wantFactsErr error
+		wantSkipped  []string
 	}{
 		{
 			name: "empty",
@@ -105,6 +114,41 @@ func TestPatchBuilder(t *testing.T) {
 				"storage_path":  (*int64)(nil),
 			},
 		},
+		{
+			name: "protected fields",
+			doc: plclient.Document{
+				Title:         "original",
+				Correspondent: &firstCorrespondent.ID,
+			},
+			protected: []string{paperminer.FieldTitle, paperminer.FieldCorrespondent, paperminer.FieldStoragePath},
+			facts: &paperminer.Facts{
+				Title:         ref.Ref("changed"),
+				Correspondent: ref.Ref(""),
+				DocumentType:  ref.Ref(firstDocumentType.Name),
+				SetTags:       []string{firstTag.Name},
+			},
+			want: map[string]any{
+				"document_type": &firstDocumentType.ID,
+				"tags":          []int64{firstTag.ID},
+			},
+			wantSkipped: []string{paperminer.FieldTitle, paperminer.FieldCorrespondent},
+		},
+		{
+			name: "forced fields",
+			doc: plclient.Document{
+				Title: "original",
+			},
+			protected: []string{paperminer.FieldTitle, paperminer.FieldCreated},
+			facts: &paperminer.Facts{
+				Force:   []string{paperminer.FieldTitle},
+				Title:   ref.Ref("changed"),
+				Created: ref.Ref(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)),
+			},
+			want: map[string]any{
+				"title": "changed",
+			},
+			wantSkipped: []string{paperminer.FieldCreated},
+		},
 		{
 			name: "facts with tags",
 			doc: plclient.Document{
@@ -138,12 +182,86 @@ func TestPatchBuilder(t *testing.T) {
 			wantFactsErr: objectresolver.ErrNotFound,
 			want:         map[string]any{},
 		},
+		{
+			name: "custom fields",
+			doc: plclient.Document{
+				CustomFields: []plclient.CustomFieldInstance{
+					{Field: paidField.ID, Value: false},
+					{Field: dueField.ID, Value: "2020-01-01"},
+				},
+			},
+			facts: &paperminer.Facts{
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					totalField.Name: {
+						Monetary: &paperminer.MonetaryValue{Currency: "eur", Amount: 12.3},
+					},
+					dueField.Name: {
+						Date: ref.Ref(time.Date(2020, time.February, 3, 0, 0, 0, 0, time.UTC)),
+					},
+				},
+			},
+			want: map[string]any{
+				"custom_fields": []plclient.CustomFieldInstance{
+					{Field: paidField.ID, Value: false},
+					{Field: dueField.ID, Value: "2020-02-03"},
+					{Field: totalField.ID, Value: "EUR12.30"},
+				},
+			},
+		},
+		{
+			name: "custom fields unchanged",
+			doc: plclient.Document{
+				CustomFields: []plclient.CustomFieldInstance{
+					{Field: totalField.ID, Value: "EUR12.30"},
+					{Field: paidField.ID, Value: true},
+				},
+			},
+			facts: &paperminer.Facts{
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					totalField.Name: {
+						Monetary: &paperminer.MonetaryValue{Currency: "EUR", Amount: 12.3},
+					},
+					paidField.Name: {Boolean: ref.Ref(true)},
+				},
+			},
+			want: map[string]any{},
+		},
+		{
+			name: "remove custom field",
+			doc: plclient.Document{
+				CustomFields: []plclient.CustomFieldInstance{
+					{Field: totalField.ID, Value: "EUR12.30"},
+					{Field: paidField.ID, Value: true},
+				},
+			},
+			facts: &paperminer.Facts{
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					totalField.Name: {},
+				},
+			},
+			want: map[string]any{
+				"custom_fields": []plclient.CustomFieldInstance{
+					{Field: paidField.ID, Value: true},
+				},
+			},
+		},
+		{
+			name: "unknown custom field",
+			facts: &paperminer.Facts{
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					"unknown field 1234": {String: ref.Ref("")},
+				},
+			},
+			wantFactsErr: objectresolver.ErrNotFound,
+			want:         map[string]any{},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			t.Cleanup(cancel)
 
 			pb := newPatchBuilder(resolvers, &tc.doc)
+			pb.protected = tc.protected
 
 			if tc.facts != nil {
 				err := pb.setFacts(ctx, tc.facts)
@@ -153,9 +271,73 @@ func TestPatchBuilder(t *testing.T) {
 				}
 			}
 
+			if diff := cmp.Diff(tc.wantSkipped, pb.skipped, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Skipped fields diff (-want +got):\n%s", diff)
+			}
+
 			if diff := cmp.Diff(tc.want, pb.build().AsMap(), testutil.CmpSortInt64Slices); diff != "" {
 				t.Errorf("Patch diff (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+type fakeCustomFieldClient struct {
+	fields []plclient.CustomField
+}
+
+func (c *fakeCustomFieldClient) ListCustomFields(_ context.Context, opts plclient.ListCustomFieldsOptions) ([]plclient.CustomField, *plclient.Response, error) {
+	var result []plclient.CustomField
+
+	for _, f := range c.fields {
+		if strings.EqualFold(f.Name, *opts.Name.EqualsIgnoringCase) {
+			result = append(result, f)
+		}
+	}
+
+	return result, nil, nil
+}
+
+func (c *fakeCustomFieldClient) GetCustomField(_ context.Context, id int64) (*plclient.CustomField, *plclient.Response, error) {
+	for _, f := range c.fields {
+		if f.ID == id {
+			return &f, nil, nil
+		}
+	}
+
+	return nil, nil, objectresolver.ErrNotFound
+}
+
+func TestPatchBuilderCustomFieldType(t *testing.T) {
+	resolvers := objectresolver.NewMemObjectResolvers()
+	resolvers.CustomField = objectresolver.NewCustomFieldResolver(objectresolver.CustomFieldResolverOptions{
+		Client: &fakeCustomFieldClient{
+			fields: []plclient.CustomField{
+				{ID: 1, Name: "reference", DataType: plclient.CustomFieldString},
+				{ID: 2, Name: "amount", DataType: plclient.CustomFieldMonetary},
+			},
+		},
+	})
+
+	pb := newPatchBuilder(resolvers, &plclient.Document{})
+
+	if err := pb.setFacts(context.Background(), &paperminer.Facts{
+		CustomFields: map[string]paperminer.CustomFieldValue{
+			"amount": {Monetary: &paperminer.MonetaryValue{Currency: "EUR", Amount: 12.5}},
+		},
+	}); err != nil {
+		t.Errorf("setFacts() failed: %v", err)
+	}
+
+	err := pb.setFacts(context.Background(), &paperminer.Facts{
+		CustomFields: map[string]paperminer.CustomFieldValue{
+			"reference": {Monetary: &paperminer.MonetaryValue{Currency: "EUR", Amount: 12.5}},
+		},
+	})
+
+	if !(errors.Is(err, paperminer.ErrPermanent) && errors.Is(err, os.ErrInvalid)) {
+		t.Errorf("setFacts() returned %v, want permanent invalid argument", err)
+	} else if !strings.Contains(err.Error(), `"reference"`) {
+		t.Errorf("Error %q doesn't name the field", err)
+	}
+}
@@ -0,0 +1,103 @@
+package cataloger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"go.uber.org/zap"
+)
+
+// processResponse is the result of processing a document on request.
+type processResponse struct {
+	DocumentID int64 `json:"document_id"`
+	DryRun     bool  `json:"dry_run,omitempty"`
+
+	processResult
+}
+
+type processNowFunc func(context.Context, int64) (*processResponse, error)
+
+// newProcessHandler returns an HTTP handler processing the document given in
+// the "id" URL parameter and responding with the result as JSON.
+func newProcessHandler(logger *zap.Logger, process processNowFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil || id < 1 {
+			http.Error(w, "invalid document ID", http.StatusBadRequest)
+			return
+		}
+
+		logger := logger.With(zap.Int64("document_id", id))
+
+		result, err := process(r.Context(), id)
+		if err != nil {
+			var clientReqErr *plclient.RequestError
+
+			status := http.StatusInternalServerError
+
+			if errors.As(err, &clientReqErr) && clientReqErr.StatusCode == http.StatusNotFound {
+				status = http.StatusNotFound
+			} else if errors.Is(err, errDocumentBusy) {
+				status = http.StatusConflict
+			}
+
+			logger.Error("Processing document on request failed", zap.Error(err))
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			logger.Error("Writing response failed", zap.Error(err))
+		}
+	}
+}
+
+// processDocumentNow runs the pipeline for a document regardless of its tags
+// and a pending retry. [errDocumentBusy] is returned if the document is being
+// processed already.
+func (w *workflow) processDocumentNow(ctx context.Context, id int64) (*processResponse, error) {
+	logger := w.env.Logger().With(zap.Int64("document_id", id))
+
+	doc, _, err := w.env.Client().GetDocument(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting document %d: %w", id, err)
+	}
+
+	logger.Info("Processing document on request")
+
+	result := &processResponse{
+		DocumentID: id,
+		DryRun:     w.dryRun,
+	}
+
+	if err := w.runTaskWithOptions(ctx, doc, taskOptions{
+		Logger:           logger,
+		IgnoreRetryAfter: true,
+	}, func(ctx context.Context, logger *zap.Logger, t *task) error {
+		u, err := w.newProcessUpdater(ctx, logger, t)
+		if err != nil {
+			result.Outcome = outcomeRetry
+			result.Error = err.Error()
+
+			return err
+		}
+
+		err = u.Do(ctx, t.RetryCount() >= w.retriesMax)
+
+		result.processResult = u.Result()
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
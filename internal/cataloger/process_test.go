@@ -0,0 +1,114 @@
+package cataloger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/go-cmp/cmp"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestProcessHandler(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantBody   map[string]any
+	}{
+		{
+			name:       "success",
+			path:       "/documents/123/process",
+			wantStatus: http.StatusOK,
+			wantBody: map[string]any{
+				"document_id": float64(123),
+				"outcome":     outcomeUpdated,
+				"facts": map[string]any{
+					"reporter": "test",
+					"title":    "Invoice",
+				},
+				"patch": map[string]any{
+					"title": "Invoice",
+				},
+			},
+		},
+		{
+			name:       "invalid ID",
+			path:       "/documents/abc/process",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "negative ID",
+			path:       "/documents/-1/process",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			path:       "/documents/123/process",
+			err:        &plclient.RequestError{StatusCode: http.StatusNotFound},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "busy",
+			path:       "/documents/123/process",
+			err:        errDocumentBusy,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "failure",
+			path:       "/documents/123/process",
+			err:        errors.New("test"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := chi.NewRouter()
+			mux.Post("/documents/{id}/process", newProcessHandler(zaptest.NewLogger(t),
+				func(_ context.Context, id int64) (*processResponse, error) {
+					if tc.err != nil {
+						return nil, tc.err
+					}
+
+					return &processResponse{
+						DocumentID: id,
+						processResult: processResult{
+							Outcome: outcomeUpdated,
+							Facts: &paperminer.Facts{
+								Reporter: ref.Ref("test"),
+								Title:    ref.Ref("Invoice"),
+							},
+							Patch: plclient.NewDocumentFields().SetTitle("Invoice").AsMap(),
+						},
+					}, nil
+				}))
+
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+
+			if tc.wantBody != nil {
+				var got map[string]any
+
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Errorf("Unmarshal() failed: %v", err)
+				}
+
+				if diff := cmp.Diff(tc.wantBody, got); diff != "" {
+					t.Errorf("Body diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package cataloger
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+)
+
+// Policies for protecting document fields from being overwritten by facts.
+const (
+	// Overwrite all fields.
+	fieldProtectionNone = "none"
+
+	// Keep fields changed by a user after the document was consumed, as
+	// recorded in the document history.
+	fieldProtectionEdited = "edited"
+
+	// Keep a title differing from what Paperless assigns when consuming
+	// a document.
+	fieldProtectionNonDefault = "non-default"
+
+	// Keep all fields unless forced.
+	fieldProtectionAll = "all"
+)
+
+var fieldProtectionPolicies = []string{
+	fieldProtectionNone,
+	fieldProtectionEdited,
+	fieldProtectionNonDefault,
+	fieldProtectionAll,
+}
+
+// Fields which can be protected, in the order in which they're reported.
+var protectableFields = []string{
+	paperminer.FieldTitle,
+	paperminer.FieldCreated,
+	paperminer.FieldDocumentType,
+	paperminer.FieldCorrespondent,
+	paperminer.FieldStoragePath,
+}
+
+// Actions in the document history.
+const (
+	historyActionCreate = "create"
+	historyActionUpdate = "update"
+)
+
+// defaultTitle returns the title Paperless assigns to consumed documents
+// without an explicit title, i.e. the original filename without extension.
+func defaultTitle(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename))
+}
+
+// nonDefaultFields returns the fields no longer having the value Paperless
+// assigns on consumption. Only the title has a known default.
+func nonDefaultFields(doc *plclient.Document) []string {
+	if doc.OriginalFileName != "" && doc.Title != defaultTitle(doc.OriginalFileName) {
+		return []string{paperminer.FieldTitle}
+	}
+
+	return nil
+}
+
+// editedFields returns the fields changed by a user other than the given one
+// after the document was created. Changes without an actor are made by
+// Paperless itself, e.g. by workflows, and are ignored.
+func editedFields(history []plclient.DocumentHistoryEntry, selfID int64) []string {
+	var consumed *plclient.DocumentHistoryEntry
+
+	for idx, i := range history {
+		if i.Action == historyActionCreate {
+			consumed = &history[idx]
+		}
+	}
+
+	changed := map[string]struct{}{}
+
+	for _, i := range history {
+		if i.Action != historyActionUpdate || i.Actor == nil || i.Actor.ID == selfID {
+			continue
+		}
+
+		if consumed != nil && i.Timestamp.Before(consumed.Timestamp) {
+			continue
+		}
+
+		for field := range i.Changes {
+			changed[field] = struct{}{}
+		}
+	}
+
+	var result []string
+
+	for _, field := range protectableFields {
+		if _, ok := changed[field]; ok {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
+// protectedFields returns the names of the document fields which must not be
+// overwritten according to a protection policy. The history is only used by
+// [fieldProtectionEdited].
+func protectedFields(policy string, doc *plclient.Document, history []plclient.DocumentHistoryEntry, selfID int64) []string {
+	switch policy {
+	case fieldProtectionEdited:
+		return editedFields(history, selfID)
+
+	case fieldProtectionNonDefault:
+		return nonDefaultFields(doc)
+
+	case fieldProtectionAll:
+		return slices.Clone(protectableFields)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package cataloger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+)
+
+func TestProtectedFields(t *testing.T) {
+	consumed := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name    string
+		policy  string
+		doc     plclient.Document
+		history []plclient.DocumentHistoryEntry
+		want    []string
+	}{
+		{
+			name:   "none",
+			policy: fieldProtectionNone,
+			doc: plclient.Document{
+				Title:            "changed",
+				OriginalFileName: "scan.pdf",
+				Correspondent:    plclient.Int64(1),
+			},
+		},
+		{
+			name:   "defaults",
+			policy: fieldProtectionNonDefault,
+			doc: plclient.Document{
+				Title:            "scan.2024",
+				OriginalFileName: "scan.2024.pdf",
+			},
+		},
+		{
+			name:   "unknown filename",
+			policy: fieldProtectionNonDefault,
+			doc: plclient.Document{
+				Title: "title",
+			},
+		},
+		{
+			name:   "assigned objects",
+			policy: fieldProtectionNonDefault,
+			doc: plclient.Document{
+				Title:            "Invoice",
+				OriginalFileName: "scan.pdf",
+				Correspondent:    plclient.Int64(1),
+				DocumentType:     plclient.Int64(2),
+				StoragePath:      plclient.Int64(3),
+			},
+			want: []string{paperminer.FieldTitle},
+		},
+		{
+			name:   "edited without history",
+			policy: fieldProtectionEdited,
+			doc: plclient.Document{
+				Title:            "Invoice",
+				OriginalFileName: "scan.pdf",
+				Correspondent:    plclient.Int64(1),
+			},
+		},
+		{
+			name:   "edited",
+			policy: fieldProtectionEdited,
+			history: []plclient.DocumentHistoryEntry{
+				{
+					Timestamp: consumed.Add(3 * time.Hour),
+					Action:    historyActionUpdate,
+					Changes:   map[string]any{"correspondent": []any{"None", "3"}, "tags": []any{}},
+					Actor:     &plclient.DocumentHistoryActor{ID: 2, Username: "user"},
+				},
+				{
+					Timestamp: consumed.Add(2 * time.Hour),
+					Action:    historyActionUpdate,
+					Changes:   map[string]any{"title": []any{"scan", "Invoice"}},
+					Actor:     &plclient.DocumentHistoryActor{ID: 1, Username: "paperminer"},
+				},
+				{
+					Timestamp: consumed.Add(time.Hour),
+					Action:    historyActionUpdate,
+					Changes:   map[string]any{"created": []any{"2024-01-01", "2024-02-02"}},
+					Actor:     &plclient.DocumentHistoryActor{ID: 2, Username: "user"},
+				},
+				{
+					Timestamp: consumed.Add(time.Minute),
+					Action:    historyActionUpdate,
+					Changes:   map[string]any{"document_type": []any{"None", "4"}},
+				},
+				{
+					Timestamp: consumed,
+					Action:    historyActionCreate,
+					Changes:   map[string]any{"title": []any{"None", "scan"}, "storage_path": []any{"None", "5"}},
+				},
+				{
+					Timestamp: consumed.Add(-time.Hour),
+					Action:    historyActionUpdate,
+					Changes:   map[string]any{"storage_path": []any{"None", "5"}},
+					Actor:     &plclient.DocumentHistoryActor{ID: 2, Username: "user"},
+				},
+			},
+			want: []string{
+				paperminer.FieldCreated,
+				paperminer.FieldCorrespondent,
+			},
+		},
+		{
+			name:   "all",
+			policy: fieldProtectionAll,
+			doc: plclient.Document{
+				Title:            "scan",
+				OriginalFileName: "scan.pdf",
+			},
+			want: []string{
+				paperminer.FieldTitle,
+				paperminer.FieldCreated,
+				paperminer.FieldDocumentType,
+				paperminer.FieldCorrespondent,
+				paperminer.FieldStoragePath,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := protectedFields(tc.policy, &tc.doc, tc.history, 1)
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("protectedFields() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
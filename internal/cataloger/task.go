@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	plclient "github.com/hansmi/paperhooks/pkg/client"
@@ -26,13 +27,12 @@ func convertToJsonNode(data any) (jd.JsonNode, error) {
 	return jd.ReadJsonString(string(buf))
 }
 
+// docSnapshot contains the document properties from which facts are derived.
+// Changes invalidate the facts. Other fields are merged (see mergePatch).
 type docSnapshot struct {
-	ID       int64
-	Added    time.Time
-	Created  time.Time
-	Modified time.Time
-	Title    string
-	Content  string
+	ID      int64
+	Added   time.Time
+	Content string
 
 	OriginalChecksum  string
 	HasArchiveVersion bool
@@ -41,12 +41,9 @@ type docSnapshot struct {
 
 func newDocSnapshot(doc *plclient.Document, metadata *plclient.DocumentMetadata) docSnapshot {
 	return docSnapshot{
-		ID:       doc.ID,
-		Added:    doc.Added,
-		Created:  doc.Created,
-		Modified: doc.Modified,
-		Title:    doc.Title,
-		Content:  doc.Content,
+		ID:      doc.ID,
+		Added:   doc.Added,
+		Content: doc.Content,
 
 		OriginalChecksum:  metadata.OriginalChecksum,
 		HasArchiveVersion: metadata.HasArchiveVersion,
@@ -69,6 +66,12 @@ type taskOptions struct {
 	Store  taskStore
 	Client taskClient
 
+	// Process documents even if their retry time has not been reached yet.
+	IgnoreRetryAfter bool
+
+	// Don't record the outcome in the store.
+	DryRun bool
+
 	clock clockwork.Clock
 }
 
@@ -80,6 +83,9 @@ type task struct {
 
 	snapshot docSnapshot
 
+	// Document as loaded, used as the base for merging patches.
+	base plclient.Document
+
 	begin time.Time
 
 	key []byte
@@ -128,7 +134,7 @@ func loadTask(ctx context.Context, doc *plclient.Document, opts taskOptions) (*t
 		t.rec.RecordCreated = t.begin
 	} else if err != nil {
 		return nil, fmt.Errorf("getting record for %q: %w", t.key, err)
-	} else if retryAfter := t.rec.RetryAfter; !retryAfter.IsZero() && retryAfter.After(t.begin) {
+	} else if retryAfter := t.rec.RetryAfter; !opts.IgnoreRetryAfter && !retryAfter.IsZero() && retryAfter.After(t.begin) {
 		opts.Logger.Info("Document failed previously and retry time has not been reached yet",
 			zap.Time("retry_after", retryAfter),
 			zap.Any("attempts", t.rec.Attempts))
@@ -136,6 +142,9 @@ func loadTask(ctx context.Context, doc *plclient.Document, opts taskOptions) (*t
 	}
 
 	t.snapshot = newDocSnapshot(doc, metadata)
+	t.base = *doc
+	t.base.Tags = slices.Clone(doc.Tags)
+	t.base.CustomFields = slices.Clone(doc.CustomFields)
 
 	return t, nil
 }
@@ -144,34 +153,43 @@ func (t *task) RetryCount() int {
 	return t.rec.RetryCount
 }
 
-func (t *task) CheckModified(ctx context.Context) error {
+// Attempt returns the number of the current processing attempt, starting at 1.
+func (t *task) Attempt() int {
+	return len(t.rec.Attempts) + 1
+}
+
+// CheckModified fetches the current document and rebases a patch built for
+// the document as loaded onto it. Concurrent changes to fields not touched by
+// the patch are retained. Changes to the content or files and conflicting
+// changes to fields fail with errConcurrentModification.
+func (t *task) CheckModified(ctx context.Context, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
 	curDoc, _, err := t.opts.Client.GetDocument(ctx, t.doc.ID)
 	if err != nil {
-		return fmt.Errorf("getting document %d for conflict check: %w", t.doc.ID, err)
+		return nil, fmt.Errorf("getting document %d for conflict check: %w", t.doc.ID, err)
 	}
 
 	curMetadata, _, err := t.opts.Client.GetDocumentMetadata(ctx, t.doc.ID)
 	if err != nil {
-		return fmt.Errorf("getting document %d metadata for conflict check: %w", t.doc.ID, err)
+		return nil, fmt.Errorf("getting document %d metadata for conflict check: %w", t.doc.ID, err)
 	}
 
 	originalSnapshotNode, err := convertToJsonNode(t.snapshot)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	curSnapshot := newDocSnapshot(curDoc, curMetadata)
 
 	curSnapshotNode, err := convertToJsonNode(curSnapshot)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if diff := originalSnapshotNode.Diff(curSnapshotNode); len(diff) > 0 {
-		return fmt.Errorf("%w:\n%s", errConcurrentModification, diff.Render())
+		return nil, fmt.Errorf("%w:\n%s", errConcurrentModification, diff.Render())
 	}
 
-	return nil
+	return mergePatch(&t.base, curDoc, patch)
 }
 
 func (t *task) SaveResult(err error, retryAfter time.Duration) error {
@@ -225,6 +243,10 @@ func processDocument(ctx context.Context,
 		)
 	}
 
+	if opts.DryRun {
+		return nil
+	}
+
 	if err := task.SaveResult(processErr, retryDelay); err != nil {
 		return fmt.Errorf("saving processing result: %w", err)
 	}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	plclient "github.com/hansmi/paperhooks/pkg/client"
 	"github.com/hansmi/paperminer/internal/ref"
 	"github.com/hansmi/paperminer/internal/store"
+	"github.com/hansmi/paperminer/internal/testutil"
 	"github.com/jonboulle/clockwork"
 	"github.com/timshannon/bolthold"
 	"go.uber.org/zap"
@@ -97,7 +99,11 @@ func TestLoadTask(t *testing.T) {
 		t.Errorf("RetryCount() = %d, want zero", got)
 	}
 
-	if err := task.CheckModified(ctx); err != nil {
+	if got := task.Attempt(); got != 1 {
+		t.Errorf("Attempt() = %d, want 1", got)
+	}
+
+	if _, err := task.CheckModified(ctx, plclient.NewDocumentFields()); err != nil {
 		t.Errorf("CheckModified() failed: %v", err)
 	}
 
@@ -111,6 +117,10 @@ func TestLoadTask(t *testing.T) {
 		t.Fatalf("loadTask() failed: %v", err)
 	}
 
+	if got := task.Attempt(); got != 2 {
+		t.Errorf("Attempt() = %d, want 2", got)
+	}
+
 	if diff := cmp.Diff(store.DocumentTask{
 		RecordCreated: time.Unix(1234567890, 0),
 		RecordUpdated: time.Unix(1234567890+60, 0),
@@ -161,22 +171,65 @@ func TestLoadTask(t *testing.T) {
 	} else if task != nil {
 		t.Errorf("loadTask() returned non-nil value even though retry time hasn't passed yet: %#v", task)
 	}
+
+	taskOpts.IgnoreRetryAfter = true
+
+	if task, err = loadTask(ctx, doc, taskOpts); err != nil {
+		t.Fatalf("loadTask() failed: %v", err)
+	} else if task == nil {
+		t.Errorf("loadTask() returned nil despite ignoring the retry time")
+	} else if got := task.Attempt(); got != 3 {
+		t.Errorf("Attempt() = %d, want 3", got)
+	}
 }
 
 func TestTaskCheckModified(t *testing.T) {
 	for _, tc := range []struct {
-		name    string
-		mod     func(*fakeTaskClient)
-		wantErr error
+		name      string
+		doc       plclient.Document
+		mod       func(*fakeTaskClient)
+		patch     *plclient.DocumentFields
+		want      map[string]any
+		wantErr   error
+		wantInErr string
 	}{
 		{
 			name: "defaults",
+			want: map[string]any{},
+		},
+		{
+			name: "unrelated field",
+			mod: func(c *fakeTaskClient) {
+				c.doc.Title = "changed"
+				c.doc.Modified = time.Unix(1000, 0)
+			},
+			patch: plclient.NewDocumentFields().SetCorrespondent(plclient.Int64(1)),
+			want: map[string]any{
+				"correspondent": plclient.Int64(1),
+			},
 		},
 		{
-			name: "doc only",
+			name: "same field",
 			mod: func(c *fakeTaskClient) {
 				c.doc.Title = "changed"
 			},
+			patch:     plclient.NewDocumentFields().SetTitle("facts").SetStoragePath(nil),
+			wantErr:   errConcurrentModification,
+			wantInErr: "conflicting changes to title",
+		},
+		{
+			name: "same value",
+			mod: func(c *fakeTaskClient) {
+				c.doc.Title = "facts"
+			},
+			patch: plclient.NewDocumentFields().SetTitle("facts"),
+			want:  map[string]any{},
+		},
+		{
+			name: "content",
+			mod: func(c *fakeTaskClient) {
+				c.doc.Content = "changed"
+			},
 			wantErr: errConcurrentModification,
 		},
 		{
@@ -194,18 +247,92 @@ func TestTaskCheckModified(t *testing.T) {
 			},
 			wantErr: errConcurrentModification,
 		},
+		{
+			name: "tags",
+			doc: plclient.Document{
+				Tags: []int64{1, 2, 3},
+			},
+			mod: func(c *fakeTaskClient) {
+				c.doc.Tags = []int64{2, 3, 4, 10}
+			},
+			patch: plclient.NewDocumentFields().SetTags([]int64{3, 5, 10}),
+			want: map[string]any{
+				"tags": []int64{3, 4, 5, 10},
+			},
+		},
+		{
+			name: "tags changed the same way",
+			doc: plclient.Document{
+				Tags: []int64{1},
+			},
+			mod: func(c *fakeTaskClient) {
+				c.doc.Tags = []int64{2}
+			},
+			patch: plclient.NewDocumentFields().SetTags([]int64{2}),
+			want:  map[string]any{},
+		},
+		{
+			name: "custom fields",
+			doc: plclient.Document{
+				CustomFields: []plclient.CustomFieldInstance{
+					{Field: 1, Value: "a"},
+					{Field: 2, Value: 100},
+					{Field: 3, Value: true},
+				},
+			},
+			mod: func(c *fakeTaskClient) {
+				c.doc.CustomFields = []plclient.CustomFieldInstance{
+					{Field: 1, Value: "a"},
+					{Field: 2, Value: float64(100)},
+					{Field: 3, Value: false},
+					{Field: 4, Value: "user"},
+				}
+			},
+			patch: plclient.NewDocumentFields().SetCustomFields([]plclient.CustomFieldInstance{
+				{Field: 2, Value: 200},
+				{Field: 3, Value: true},
+				{Field: 5, Value: "new"},
+			}),
+			want: map[string]any{
+				"custom_fields": []plclient.CustomFieldInstance{
+					{Field: 3, Value: false},
+					{Field: 4, Value: "user"},
+					{Field: 2, Value: 200},
+					{Field: 5, Value: "new"},
+				},
+			},
+		},
+		{
+			name: "custom field conflict",
+			doc: plclient.Document{
+				CustomFields: []plclient.CustomFieldInstance{
+					{Field: 1, Value: "a"},
+				},
+			},
+			mod: func(c *fakeTaskClient) {
+				c.doc.CustomFields = []plclient.CustomFieldInstance{
+					{Field: 1, Value: "b"},
+				}
+				c.doc.DocumentType = plclient.Int64(7)
+			},
+			patch: plclient.NewDocumentFields().SetDocumentType(plclient.Int64(8)).SetCustomFields([]plclient.CustomFieldInstance{
+				{Field: 1, Value: "c"},
+			}),
+			wantErr:   errConcurrentModification,
+			wantInErr: "conflicting changes to custom field 1, document_type",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			t.Cleanup(cancel)
 
 			s := &fakeTaskStore{t: t}
-			client := &fakeTaskClient{}
+			client := &fakeTaskClient{
+				doc: tc.doc,
+			}
 
 			clock := clockwork.NewFakeClockAt(time.Unix(987654321, 0))
 
-			doc := &plclient.Document{}
-
 			taskOpts := taskOptions{
 				Logger: zaptest.NewLogger(t),
 				Store:  s,
@@ -213,12 +340,12 @@ func TestTaskCheckModified(t *testing.T) {
 				clock:  clock,
 			}
 
-			task, err := loadTask(ctx, doc, taskOpts)
+			task, err := loadTask(ctx, ref.Ref(tc.doc), taskOpts)
 			if err != nil {
 				t.Fatalf("loadTask() failed: %v", err)
 			}
 
-			if err := task.CheckModified(ctx); err != nil {
+			if _, err := task.CheckModified(ctx, plclient.NewDocumentFields()); err != nil {
 				t.Errorf("CheckModified() failed: %v", err)
 			}
 
@@ -226,11 +353,23 @@ func TestTaskCheckModified(t *testing.T) {
 				tc.mod(client)
 			}
 
-			err = task.CheckModified(ctx)
+			if tc.patch == nil {
+				tc.patch = plclient.NewDocumentFields()
+			}
+
+			got, err := task.CheckModified(ctx, tc.patch)
 
 			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("Error diff (-want +got):\n%s", diff)
 			}
+
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.wantInErr) {
+					t.Errorf("Error %q doesn't contain %q", err.Error(), tc.wantInErr)
+				}
+			} else if diff := cmp.Diff(tc.want, got.AsMap(), testutil.CmpSortInt64Slices); diff != "" {
+				t.Errorf("Patch diff (-want +got):\n%s", diff)
+			}
 		})
 	}
 }
@@ -270,7 +409,7 @@ func TestProcessDocument(t *testing.T) {
 
 			err = processDocument(ctx, &client.doc, opts,
 				func(ctx context.Context, _ *zap.Logger, task *task) error {
-					if err := task.CheckModified(ctx); err != nil {
+					if _, err := task.CheckModified(ctx, plclient.NewDocumentFields()); err != nil {
 						t.Errorf("CheckModified() failed: %v", err)
 					}
 
@@ -287,3 +426,49 @@ func TestProcessDocument(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessDocumentDryRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "data"), 0)
+	if err != nil {
+		t.Errorf("Opening store failed: %v", err)
+	}
+
+	client := &fakeTaskClient{}
+
+	opts := taskOptions{
+		Logger: zaptest.NewLogger(t),
+		Store:  s,
+		Client: client,
+		DryRun: true,
+		clock:  clockwork.NewFakeClockAt(time.Unix(1234567890, 0)),
+	}
+
+	for range 3 {
+		if err := processDocument(ctx, &client.doc, opts,
+			func(context.Context, *zap.Logger, *task) error {
+				return errors.New("test error")
+			},
+			func(int) time.Duration {
+				return time.Hour
+			},
+		); err != nil {
+			t.Errorf("processDocument() failed: %v", err)
+		}
+	}
+
+	task, err := loadTask(ctx, &client.doc, opts)
+	if err != nil {
+		t.Fatalf("loadTask() failed: %v", err)
+	}
+
+	if task == nil {
+		t.Fatalf("loadTask() returned no task, retry delay was recorded")
+	}
+
+	if got := task.Attempt(); got != 1 {
+		t.Errorf("Attempt() = %d, want 1", got)
+	}
+}
@@ -10,7 +10,10 @@ import (
 	plclient "github.com/hansmi/paperhooks/pkg/client"
 	"github.com/hansmi/paperminer"
 	"github.com/hansmi/paperminer/internal/document"
+	"github.com/hansmi/paperminer/internal/facter"
 	"github.com/hansmi/paperminer/internal/objectresolver"
+	"github.com/hansmi/paperminer/internal/store"
+	"github.com/timshannon/bolthold"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
@@ -21,9 +24,22 @@ type updaterClient interface {
 	document.VariantFactsClient
 
 	PatchDocument(context.Context, int64, *plclient.DocumentFields) (*plclient.Document, *plclient.Response, error)
+	ListDocumentNotes(context.Context, int64) ([]plclient.DocumentNote, *plclient.Response, error)
+	CreateDocumentNote(context.Context, int64, *plclient.DocumentNoteFields) (*plclient.DocumentNote, *plclient.Response, error)
+	GetDocumentHistory(context.Context, int64) ([]plclient.DocumentHistoryEntry, *plclient.Response, error)
 }
 
-type updaterModificationCheckFunc func(context.Context) error
+type suggestionStore interface {
+	Get(any, any) error
+	Upsert(any, any) error
+	Delete(any, any) error
+}
+
+// updaterModificationCheckFunc returns a patch rebased onto the current
+// version of the document or an error if it was modified in a conflicting way.
+type updaterModificationCheckFunc func(context.Context, *plclient.DocumentFields) (*plclient.DocumentFields, error)
+
+type updaterContentFactsFunc func(context.Context, *zap.Logger, *paperminer.DocumentInfo, string) (facter.FactsSlice, error)
 
 type updaterOptions struct {
 	Logger    *zap.Logger
@@ -38,10 +54,70 @@ type updaterOptions struct {
 	FailedTagName string
 	FileSizeMax   int64
 
-	ExtractTimeout   time.Duration
-	ExtractFileFacts document.ExtractFileFactsFunc
+	// Number of the current processing attempt, starting at 1.
+	Attempt int
+
+	// Names of the facters used for extraction.
+	FacterNames []string
+
+	ExtractTimeout time.Duration
+
+	// Function extracting facts from the document content provided by
+	// Paperless. Facts from the content take precedence and avoid downloading
+	// the document. May be nil.
+	ExtractContentFacts updaterContentFactsFunc
+
+	// Function extracting facts from a downloaded document file. May be nil.
+	ExtractFileFacts   document.ExtractFileFactsFunc
+	ExtractAllVariants bool
 
 	CheckModified updaterModificationCheckFunc
+
+	// Propose all facts for review instead of applying them. Facts marked as
+	// suggestions (see [paperminer.Facts.Suggest]) are always proposed.
+	Suggest bool
+
+	// Tags marking documents with suggested facts and approving them.
+	ReviewTagName  string
+	ApproveTagName string
+
+	// Store for suggested facts, keyed by document ID.
+	Store suggestionStore
+
+	// Policy for protecting fields from being overwritten by facts.
+	FieldProtection string
+
+	// ID of the Paperless user applying facts. Changes by this user in the
+	// document history are not considered edits.
+	UserID int64
+
+	// Log changes instead of applying them. Resolvers should be in no-create
+	// mode.
+	DryRun bool
+}
+
+// Outcomes of processing a document.
+const (
+	outcomeUnchanged = "unchanged"
+	outcomeUpdated   = "updated"
+	outcomeSuggested = "suggested"
+	outcomeFailed    = "failed"
+	outcomeRetry     = "retry"
+)
+
+// processResult describes the outcome of processing a document.
+type processResult struct {
+	Outcome string `json:"outcome"`
+
+	// Facts selected for the document. Nil if none were found.
+	Facts *paperminer.Facts `json:"facts"`
+
+	// Changes sent to Paperless, or only logged in dry-run mode. Nil if the
+	// document was left unchanged.
+	Patch map[string]any `json:"patch"`
+
+	// Error causing a retry or a permanent failure.
+	Error string `json:"error,omitempty"`
 }
 
 type updater struct {
@@ -49,6 +125,11 @@ type updater struct {
 
 	todoTag   *plclient.Tag
 	failedTag *plclient.Tag
+
+	// Document variants from which extracting facts was attempted.
+	triedVariants []document.Variant
+
+	result processResult
 }
 
 func newUpdater(ctx context.Context, opts updaterOptions) (*updater, error) {
@@ -94,13 +175,25 @@ func (u *updater) checkSize() error {
 }
 
 func (u *updater) getFacts(ctx context.Context, hasArchiveVersion bool) (*paperminer.Facts, error) {
+	info := newDocumentInfo(ctx, u.Logger, u.Resolvers, u.Document, u.Metadata)
+
 	var variants []document.Variant
+	var sizeErr error
 
-	if hasArchiveVersion {
-		variants = append(variants, document.Archived)
+	if u.ExtractContentFacts != nil {
+		variants = append(variants, document.Content)
 	}
 
-	variants = append(variants, document.Original)
+	if u.ExtractFileFacts != nil {
+		// Content facts may be sufficient even for an oversized document.
+		if sizeErr = u.checkSize(); sizeErr == nil {
+			if hasArchiveVersion {
+				variants = append(variants, document.Archived)
+			}
+
+			variants = append(variants, document.Original)
+		}
+	}
 
 	if u.ExtractTimeout > 0 {
 		var cancel context.CancelFunc
@@ -108,21 +201,35 @@ func (u *updater) getFacts(ctx context.Context, hasArchiveVersion bool) (*paperm
 		defer cancel()
 	}
 
-	return document.ExtractFacts(ctx, document.ExtractFactsOptions{
-		Logger:   u.Logger,
-		Variants: variants,
-		Extract: func(ctx context.Context, v document.Variant) (*paperminer.Facts, error) {
+	facts, err := document.ExtractFacts(ctx, document.ExtractFactsOptions{
+		Logger:      u.Logger,
+		Variants:    variants,
+		AllVariants: u.ExtractAllVariants,
+		Extract: func(ctx context.Context, v document.Variant) (facter.FactsSlice, error) {
 			logger := u.Logger.With(zap.Stringer("document_variant", v))
 
+			u.triedVariants = append(u.triedVariants, v)
+
+			if v == document.Content {
+				return u.ExtractContentFacts(ctx, logger, info, u.Document.Content)
+			}
+
 			return document.ExtractVariantFacts(ctx, document.ExtractVariantFactsOptions{
 				Logger:  logger,
 				Client:  u.Client,
 				Extract: u.ExtractFileFacts,
 				ID:      u.Document.ID,
 				Variant: v,
+				Info:    info,
 			})
 		},
 	})
+
+	if facts == nil && sizeErr != nil {
+		return nil, multierr.Append(sizeErr, err)
+	}
+
+	return facts, err
 }
 
 func (u *updater) patchDocument(ctx context.Context, patch *plclient.DocumentFields) error {
@@ -130,32 +237,94 @@ func (u *updater) patchDocument(ctx context.Context, patch *plclient.DocumentFie
 		return nil
 	}
 
-	if err := u.CheckModified(ctx); err != nil {
+	patch, err := u.CheckModified(ctx, patch)
+	if err != nil {
 		return err
 	}
 
+	if len(patch.AsMap()) == 0 {
+		u.Logger.Info("Changes were already made concurrently")
+		return nil
+	}
+
+	u.result.Patch = patch.AsMap()
+
+	if u.DryRun {
+		u.Logger.Info("Dry run, not patching document",
+			zap.Any("patch", patch),
+			zap.Any("would_create", u.Resolvers.Planned()))
+		return nil
+	}
+
 	u.Logger.Info("Patching document", zap.Any("patch", patch))
 
-	_, _, err := u.Client.PatchDocument(ctx, u.Document.ID, patch)
+	_, _, err = u.Client.PatchDocument(ctx, u.Document.ID, patch)
 
 	return err
 }
 
-func (u *updater) applyFacts(ctx context.Context) error {
-	if err := u.checkSize(); err != nil {
+// protectedFields returns the fields to keep according to the protection
+// policy. The document history is fetched if needed. Without a history, e.g.
+// when the Paperless audit log is disabled, a title differing from the
+// default is kept.
+func (u *updater) protectedFields(ctx context.Context) ([]string, error) {
+	var history []plclient.DocumentHistoryEntry
+
+	if u.FieldProtection == fieldProtectionEdited {
+		var err error
+		var clientReqErr *plclient.RequestError
+
+		history, _, err = u.Client.GetDocumentHistory(ctx, u.Document.ID)
+
+		if errors.As(err, &clientReqErr) && clientReqErr.StatusCode == http.StatusNotFound {
+			u.Logger.Warn("Document history unavailable, keeping only a non-default title", zap.Error(err))
+
+			return nonDefaultFields(u.Document), nil
+		} else if err != nil {
+			return nil, fmt.Errorf("getting document history: %w", err)
+		}
+	}
+
+	return protectedFields(u.FieldProtection, u.Document, history, u.UserID), nil
+}
+
+// setFacts adds facts to a patch while keeping protected fields unless the
+// facts force them.
+func (u *updater) setFacts(ctx context.Context, pb *patchBuilder, facts *paperminer.Facts) error {
+	var err error
+
+	if pb.protected, err = u.protectedFields(ctx); err != nil {
 		return err
 	}
 
+	if err := pb.setFacts(ctx, facts); err != nil {
+		return err
+	}
+
+	if len(pb.skipped) > 0 {
+		u.Logger.Info("Keeping protected fields", zap.Strings("fields", pb.skipped))
+	}
+
+	return nil
+}
+
+func (u *updater) applyFacts(ctx context.Context) error {
 	pb := newPatchBuilder(u.Resolvers, u.Document)
 
-	if facts, err := u.getFacts(ctx, u.Metadata.HasArchiveVersion); err != nil {
+	facts, err := u.getFacts(ctx, u.Metadata.HasArchiveVersion)
+
+	u.result.Facts = facts
+
+	if err != nil {
 		return err
 	} else if facts == nil || facts.IsEmpty() {
 		u.Logger.Info("No facts found, nothing to do")
+	} else if u.Suggest || facts.Suggest {
+		return u.suggestFacts(ctx, facts)
 	} else {
 		u.Logger.Info("Facts found", zap.Any("facts", facts))
 
-		if err := pb.setFacts(ctx, facts); err != nil {
+		if err := u.setFacts(ctx, pb, facts); err != nil {
 			return err
 		}
 	}
@@ -166,15 +335,237 @@ func (u *updater) applyFacts(ctx context.Context) error {
 	return u.patchDocument(ctx, pb.build())
 }
 
+// suggestFacts stores facts for review and marks the document accordingly.
+// The facts are applied once a user approves them.
+func (u *updater) suggestFacts(ctx context.Context, facts *paperminer.Facts) error {
+	u.Logger.Info("Suggesting facts for review", zap.Any("facts", facts))
+
+	reviewTag, err := u.Resolvers.Tag.GetOrCreateByName(ctx, u.ReviewTagName)
+	if err != nil {
+		return err
+	}
+
+	// Users need the tag to approve.
+	if _, err := u.Resolvers.Tag.GetOrCreateByName(ctx, u.ApproveTagName); err != nil {
+		return err
+	}
+
+	pb := newPatchBuilder(u.Resolvers, u.Document)
+	pb.unsetTag(u.todoTag.ID)
+	pb.unsetTag(u.failedTag.ID)
+	pb.setTag(reviewTag.ID)
+
+	u.result.Outcome = outcomeSuggested
+
+	if !u.DryRun {
+		if err := u.Store.Upsert(u.Document.ID, store.Suggestion{
+			ID:               u.Document.ID,
+			Facts:            *facts,
+			OriginalChecksum: u.Metadata.OriginalChecksum,
+			ArchiveChecksum:  u.Metadata.ArchiveChecksum,
+			RecordCreated:    time.Now(),
+		}); err != nil {
+			return fmt.Errorf("storing suggestion: %w", err)
+		}
+	}
+
+	if err := u.patchDocument(ctx, pb.build()); err != nil {
+		return err
+	}
+
+	note := suggestionNote{
+		facts:            facts,
+		originalChecksum: u.Metadata.OriginalChecksum,
+		archiveChecksum:  u.Metadata.ArchiveChecksum,
+		approveTag:       u.ApproveTagName,
+	}
+
+	if err := u.addSuggestionNote(ctx, note); err != nil {
+		// The review tag has been applied and the store has the facts.
+		u.Logger.Error("Adding suggestion note failed", zap.Error(err))
+	}
+
+	return nil
+}
+
+// addSuggestionNote adds a note describing suggested facts to the document
+// unless the most recent suggestion note describes the same.
+func (u *updater) addSuggestionNote(ctx context.Context, note suggestionNote) error {
+	if u.DryRun {
+		u.Logger.Info("Dry run, not adding note", zap.Stringer("note", note))
+		return nil
+	}
+
+	existing, _, err := u.Client.ListDocumentNotes(ctx, u.Document.ID)
+	if err != nil {
+		return fmt.Errorf("listing notes: %w", err)
+	}
+
+	if note.isDuplicateOf(u.latestSuggestionNote(existing)) {
+		u.Logger.Debug("Suggestion note exists already")
+		return nil
+	}
+
+	if _, _, err := u.Client.CreateDocumentNote(ctx, u.Document.ID,
+		plclient.NewDocumentNoteFields().SetNote(note.String())); err != nil {
+		return fmt.Errorf("creating note: %w", err)
+	}
+
+	return nil
+}
+
+// latestSuggestionNote returns the most recent suggestion note, if any.
+// Malformed notes are skipped.
+func (u *updater) latestSuggestionNote(notes []plclient.DocumentNote) *suggestionNote {
+	var result *suggestionNote
+	var resultID int64
+
+	for _, i := range notes {
+		if note, err := parseSuggestionNote(i.Note); err != nil {
+			u.Logger.Warn("Skipping malformed suggestion note", zap.Int64("note_id", i.ID), zap.Error(err))
+		} else if note != nil && (result == nil || i.ID > resultID) {
+			result = note
+			resultID = i.ID
+		}
+	}
+
+	return result
+}
+
+// checksumsMatch returns whether the document files are unchanged since facts
+// were suggested.
+func (u *updater) checksumsMatch(original, archive string) bool {
+	if !(original == u.Metadata.OriginalChecksum && archive == u.Metadata.ArchiveChecksum) {
+		u.Logger.Warn("Document file changed after facts were suggested")
+		return false
+	}
+
+	return true
+}
+
+// loadSuggestion returns the facts suggested for the document from the store
+// or, if not stored, the most recent suggestion note. Malformed notes are
+// skipped. Nil is returned if there are no facts or the document files
+// changed since.
+func (u *updater) loadSuggestion(ctx context.Context) (*paperminer.Facts, error) {
+	var rec store.Suggestion
+
+	if err := u.Store.Get(u.Document.ID, &rec); err == nil {
+		if !u.checksumsMatch(rec.OriginalChecksum, rec.ArchiveChecksum) {
+			return nil, nil
+		}
+
+		return &rec.Facts, nil
+	} else if !errors.Is(err, bolthold.ErrNotFound) {
+		return nil, fmt.Errorf("getting suggestion: %w", err)
+	}
+
+	notes, _, err := u.Client.ListDocumentNotes(ctx, u.Document.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing notes: %w", err)
+	}
+
+	result := u.latestSuggestionNote(notes)
+
+	if result == nil || !u.checksumsMatch(result.originalChecksum, result.archiveChecksum) {
+		return nil, nil
+	}
+
+	return result.facts, nil
+}
+
+// Approve applies the facts suggested earlier. Documents without applicable
+// suggestion are processed again.
+func (u *updater) Approve(ctx context.Context) error {
+	pb := newPatchBuilder(u.Resolvers, u.Document)
+
+	for _, name := range []string{u.ReviewTagName, u.ApproveTagName} {
+		if tag, err := u.Resolvers.Tag.GetOrCreateByName(ctx, name); err != nil {
+			return err
+		} else {
+			pb.unsetTag(tag.ID)
+		}
+	}
+
+	if facts, err := u.loadSuggestion(ctx); err != nil {
+		return err
+	} else if facts == nil {
+		u.Logger.Warn("No applicable suggestion found, processing document again")
+
+		pb.setTag(u.todoTag.ID)
+	} else {
+		u.Logger.Info("Applying approved facts", zap.Any("facts", facts))
+
+		if err := u.setFacts(ctx, pb, facts); err != nil {
+			return err
+		}
+	}
+
+	if err := u.patchDocument(ctx, pb.build()); err != nil {
+		return err
+	}
+
+	if !u.DryRun {
+		if err := u.Store.Delete(u.Document.ID, store.Suggestion{}); !(err == nil || errors.Is(err, bolthold.ErrNotFound)) {
+			u.Logger.Error("Deleting suggestion failed", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
 func (u *updater) markFailed(ctx context.Context, updateErr error) error {
 	u.Logger.Error("Document processing failed permanently", zap.Error(updateErr))
 
-	// TODO: Add note with error to document.
 	pb := newPatchBuilder(u.Resolvers, u.Document)
 	pb.unsetTag(u.todoTag.ID)
 	pb.setTag(u.failedTag.ID)
 
-	return u.patchDocument(ctx, pb.build())
+	if err := u.patchDocument(ctx, pb.build()); err != nil {
+		return err
+	}
+
+	if err := u.addFailureNote(ctx, updateErr); err != nil {
+		// The failure tag has been applied already.
+		u.Logger.Error("Adding failure note failed", zap.Error(err))
+	}
+
+	return nil
+}
+
+// addFailureNote adds a note describing a permanent failure to the document
+// unless an earlier note describes the same failure.
+func (u *updater) addFailureNote(ctx context.Context, updateErr error) error {
+	note := failureNote{
+		err:      updateErr,
+		attempts: u.Attempt,
+		facters:  u.FacterNames,
+		variants: u.triedVariants,
+	}
+
+	if u.DryRun {
+		u.Logger.Info("Dry run, not adding note", zap.Stringer("note", note))
+		return nil
+	}
+
+	existing, _, err := u.Client.ListDocumentNotes(ctx, u.Document.ID)
+	if err != nil {
+		return fmt.Errorf("listing notes: %w", err)
+	}
+
+	for _, i := range existing {
+		if note.isDuplicateOf(i.Note) {
+			u.Logger.Debug("Failure note exists already", zap.Int64("note_id", i.ID))
+			return nil
+		}
+	}
+
+	if _, _, err := u.Client.CreateDocumentNote(ctx, u.Document.ID,
+		plclient.NewDocumentNoteFields().SetNote(note.String())); err != nil {
+		return fmt.Errorf("creating note: %w", err)
+	}
+
+	return nil
 }
 
 // isPermanentError returns whether the error is deemed permanent and not
@@ -183,17 +574,37 @@ func isPermanentError(err error) bool {
 	var clientReqErr *plclient.RequestError
 
 	return (errors.Is(err, errDocumentTooLarge) ||
+		errors.Is(err, paperminer.ErrPermanent) ||
 		(errors.As(err, &clientReqErr) && clientReqErr.StatusCode == http.StatusNotFound))
 }
 
+// Result describes the outcome of processing the document.
+func (u *updater) Result() processResult {
+	return u.result
+}
+
 func (u *updater) Do(ctx context.Context, lastRetry bool) error {
 	if err := u.applyFacts(ctx); err != nil {
+		u.result.Error = err.Error()
+
 		if lastRetry || isPermanentError(err) {
+			u.result.Outcome = outcomeFailed
+
 			return u.markFailed(ctx, err)
 		}
 
+		u.result.Outcome = outcomeRetry
+
 		return err
 	}
 
+	if u.result.Outcome == "" {
+		if u.result.Patch == nil {
+			u.result.Outcome = outcomeUnchanged
+		} else {
+			u.result.Outcome = outcomeUpdated
+		}
+	}
+
 	return nil
 }
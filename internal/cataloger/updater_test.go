@@ -3,17 +3,24 @@ package cataloger
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
 	"github.com/hansmi/paperminer/internal/document"
 	"github.com/hansmi/paperminer/internal/facter"
 	"github.com/hansmi/paperminer/internal/objectresolver"
+	"github.com/hansmi/paperminer/internal/store"
 	"github.com/hansmi/paperminer/internal/testutil"
+	"github.com/timshannon/bolthold"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
@@ -78,6 +85,15 @@ func TestUpdaterCheckSize(t *testing.T) {
 
 type fakeUpdaterClient struct {
 	patches []map[string]any
+	notes   []plclient.DocumentNote
+	history []plclient.DocumentHistoryEntry
+
+	// Error returned when getting the document history.
+	historyErr error
+}
+
+func (c *fakeUpdaterClient) GetDocumentHistory(context.Context, int64) ([]plclient.DocumentHistoryEntry, *plclient.Response, error) {
+	return c.history, nil, c.historyErr
 }
 
 func (c *fakeUpdaterClient) DownloadDocumentOriginal(context.Context, io.Writer, int64) (*plclient.DownloadResult, *plclient.Response, error) {
@@ -94,6 +110,21 @@ func (c *fakeUpdaterClient) PatchDocument(_ context.Context, _ int64, fields *pl
 	return nil, nil, nil
 }
 
+func (c *fakeUpdaterClient) ListDocumentNotes(context.Context, int64) ([]plclient.DocumentNote, *plclient.Response, error) {
+	return c.notes, nil, nil
+}
+
+func (c *fakeUpdaterClient) CreateDocumentNote(_ context.Context, _ int64, fields *plclient.DocumentNoteFields) (*plclient.DocumentNote, *plclient.Response, error) {
+	note := plclient.DocumentNote{
+		ID:   int64(1 + len(c.notes)),
+		Note: fields.AsMap()["note"].(string),
+	}
+
+	c.notes = append(c.notes, note)
+
+	return &note, nil, nil
+}
+
 func TestUpdater(t *testing.T) {
 	const fileSizeMax = 1024 * 1024
 
@@ -109,38 +140,51 @@ func TestUpdater(t *testing.T) {
 		name        string
 		doc         plclient.Document
 		metadata    plclient.DocumentMetadata
+		content     updaterContentFactsFunc
 		extract     document.ExtractFileFactsFunc
+		protection  string
+		history     []plclient.DocumentHistoryEntry
+		historyErr  error
 		lastRetry   bool
 		wantErr     error
 		wantPatches []map[string]any
+		wantNotes   []*regexp.Regexp
+		wantOutcome string
 	}{
 		{
-			name: "defaults",
+			name:        "defaults",
+			wantOutcome: outcomeUnchanged,
 		},
 		{
-			name: "extraction fails",
-			extract: func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+			name:        "extraction fails",
+			wantOutcome: outcomeRetry,
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
 				return nil, errTest
 			},
 			wantErr: errTest,
 		},
 		{
-			name: "extraction fails on last retry",
-			extract: func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+			name:        "extraction fails on last retry",
+			wantOutcome: outcomeFailed,
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
 				return nil, errTest
 			},
 			lastRetry: true,
 			wantPatches: []map[string]any{{
 				"tags": []int64{failedTag.ID},
 			}},
+			wantNotes: []*regexp.Regexp{
+				regexp.MustCompile(`(?m)^Error: .*test$`),
+			},
 		},
 		{
-			name: "facts",
+			name:        "facts",
+			wantOutcome: outcomeUpdated,
 			doc: plclient.Document{
 				Title:        "original title",
 				DocumentType: plclient.Int64(1),
 			},
-			extract: func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
 				return facter.FactsSlice{{
 					Title:        plclient.String(""),
 					DocumentType: plclient.String(""),
@@ -154,7 +198,119 @@ func TestUpdater(t *testing.T) {
 			}},
 		},
 		{
-			name: "file size too large",
+			name:        "protected fields",
+			wantOutcome: outcomeUpdated,
+			doc: plclient.Document{
+				Title:            "Renamed by user",
+				OriginalFileName: "scan.pdf",
+				DocumentType:     plclient.Int64(1),
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Force:        []string{paperminer.FieldDocumentType},
+					Title:        plclient.String("Invoice"),
+					DocumentType: plclient.String(""),
+					SetTags:      []string{"custom abc"},
+				}}, nil
+			},
+			protection: fieldProtectionNonDefault,
+			wantPatches: []map[string]any{{
+				"document_type": (*int64)(nil),
+				"tags":          []int64{customTag.ID},
+			}},
+		},
+		{
+			name:        "default title not protected",
+			wantOutcome: outcomeUpdated,
+			doc: plclient.Document{
+				Title:            "scan",
+				OriginalFileName: "scan.pdf",
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title: plclient.String("Invoice"),
+				}}, nil
+			},
+			protection: fieldProtectionNonDefault,
+			wantPatches: []map[string]any{{
+				"title": "Invoice",
+			}},
+		},
+		{
+			name:        "all fields protected",
+			wantOutcome: outcomeUpdated,
+			doc: plclient.Document{
+				Title:            "scan",
+				OriginalFileName: "scan.pdf",
+				DocumentType:     plclient.Int64(1),
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Force:        []string{paperminer.FieldDocumentType},
+					Title:        plclient.String("Invoice"),
+					DocumentType: plclient.String(""),
+				}}, nil
+			},
+			protection: fieldProtectionAll,
+			wantPatches: []map[string]any{{
+				"document_type": (*int64)(nil),
+			}},
+		},
+		{
+			name:        "edited fields protected",
+			wantOutcome: outcomeUpdated,
+			doc: plclient.Document{
+				Title:            "scan",
+				OriginalFileName: "scan.pdf",
+				DocumentType:     plclient.Int64(1),
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title:        plclient.String("Invoice"),
+					DocumentType: plclient.String(""),
+				}}, nil
+			},
+			protection: fieldProtectionEdited,
+			history: []plclient.DocumentHistoryEntry{
+				{
+					Timestamp: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC),
+					Action:    historyActionUpdate,
+					Changes:   map[string]any{"document_type": []any{"None", "1"}},
+					Actor:     &plclient.DocumentHistoryActor{ID: 2, Username: "user"},
+				},
+				{
+					Timestamp: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+					Action:    historyActionCreate,
+					Changes:   map[string]any{"title": []any{"None", "scan"}},
+				},
+			},
+			wantPatches: []map[string]any{{
+				"title": "Invoice",
+			}},
+		},
+		{
+			name:        "document history unavailable",
+			wantOutcome: outcomeUpdated,
+			doc: plclient.Document{
+				Title:            "Renamed by user",
+				OriginalFileName: "scan.pdf",
+				DocumentType:     plclient.Int64(1),
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title:        plclient.String("Invoice"),
+					DocumentType: plclient.String(""),
+				}}, nil
+			},
+			protection: fieldProtectionEdited,
+			historyErr: &plclient.RequestError{StatusCode: http.StatusNotFound},
+			wantPatches: []map[string]any{{
+				"document_type": (*int64)(nil),
+			}},
+		},
+		{
+			name:        "file size too large",
+			wantOutcome: outcomeFailed,
 			metadata: plclient.DocumentMetadata{
 				OriginalSize:      fileSizeMax,
 				HasArchiveVersion: true,
@@ -163,16 +319,207 @@ func TestUpdater(t *testing.T) {
 			wantPatches: []map[string]any{{
 				"tags": []int64{failedTag.ID},
 			}},
+			wantNotes: []*regexp.Regexp{
+				regexp.MustCompile(`(?m)^Error: document too large: .*\n(?s:.*)^Attempts: 3$`),
+			},
+		},
+		{
+			name:        "content facts skip download",
+			wantOutcome: outcomeUpdated,
+			doc: plclient.Document{
+				ID:      123,
+				Content: "text",
+			},
+			content: func(_ context.Context, _ *zap.Logger, info *paperminer.DocumentInfo, content string) (facter.FactsSlice, error) {
+				if info.ID != 123 || content != "text" {
+					return nil, fmt.Errorf("unexpected content for document %d: %q", info.ID, content)
+				}
+
+				return facter.FactsSlice{{
+					Title: plclient.String("from content"),
+				}}, nil
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return nil, errTest
+			},
+			wantPatches: []map[string]any{{
+				"title": "from content",
+			}},
+		},
+		{
+			name:        "content facts for oversized document",
+			wantOutcome: outcomeUpdated,
+			metadata: plclient.DocumentMetadata{
+				OriginalSize: fileSizeMax + 1,
+			},
+			content: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, string) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title: plclient.String("from content"),
+				}}, nil
+			},
+			wantPatches: []map[string]any{{
+				"title": "from content",
+			}},
+		},
+		{
+			name:        "no content facts",
+			wantOutcome: outcomeUpdated,
+			content: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, string) (facter.FactsSlice, error) {
+				return nil, nil
+			},
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title: plclient.String("from file"),
+				}}, nil
+			},
+			wantPatches: []map[string]any{{
+				"title": "from file",
+			}},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 			t.Cleanup(cancel)
 
+			client := &fakeUpdaterClient{
+				history:    tc.history,
+				historyErr: tc.historyErr,
+			}
+
+			if tc.extract == nil {
+				tc.extract = func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+					return nil, nil
+				}
+			}
+
+			u, err := newUpdater(ctx, updaterOptions{
+				Logger:              zaptest.NewLogger(t),
+				Resolvers:           resolvers,
+				Client:              client,
+				Document:            &tc.doc,
+				Metadata:            &tc.metadata,
+				TodoTagName:         todoTag.Name,
+				FailedTagName:       failedTag.Name,
+				FileSizeMax:         fileSizeMax,
+				Attempt:             3,
+				ExtractContentFacts: tc.content,
+				ExtractFileFacts:    tc.extract,
+				CheckModified: func(_ context.Context, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
+					return patch, nil
+				},
+				FieldProtection: tc.protection,
+			})
+
+			if err != nil {
+				t.Errorf("newUpdater() failed: %v", err)
+			}
+
+			err = u.Do(ctx, tc.lastRetry)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if got := u.Result().Outcome; got != tc.wantOutcome {
+				t.Errorf("Outcome %q, want %q", got, tc.wantOutcome)
+			}
+
+			if diff := cmp.Diff(tc.wantPatches, client.patches, cmpopts.EquateEmpty(), testutil.CmpSortInt64Slices); diff != "" {
+				t.Errorf("Patches diff (-want +got):\n%s", diff)
+			}
+
+			if len(client.notes) != len(tc.wantNotes) {
+				t.Errorf("Got %d notes, want %d: %+v", len(client.notes), len(tc.wantNotes), client.notes)
+			} else {
+				for idx, want := range tc.wantNotes {
+					if got := client.notes[idx].Note; !want.MatchString(got) {
+						t.Errorf("Note %q doesn't match %q", got, want.String())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUpdaterFailureNoteDeduplication(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	client := &fakeUpdaterClient{}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		u, err := newUpdater(ctx, updaterOptions{
+			Logger:        zaptest.NewLogger(t),
+			Resolvers:     objectresolver.NewMemObjectResolvers(),
+			Client:        client,
+			Document:      &plclient.Document{},
+			Metadata:      &plclient.DocumentMetadata{OriginalSize: 100},
+			TodoTagName:   "todo",
+			FailedTagName: "failed",
+			FileSizeMax:   10,
+			Attempt:       attempt,
+			FacterNames:   []string{"first", "second"},
+			ExtractFileFacts: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return nil, nil
+			},
+			CheckModified: func(_ context.Context, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
+				return patch, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("newUpdater() failed: %v", err)
+		}
+
+		if err := u.Do(ctx, false); err != nil {
+			t.Errorf("Do() failed: %v", err)
+		}
+	}
+
+	if len(client.notes) != 1 {
+		t.Errorf("Got %d notes, want exactly one: %+v", len(client.notes), client.notes)
+	}
+}
+
+func TestUpdaterDryRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	for _, tc := range []struct {
+		name        string
+		metadata    plclient.DocumentMetadata
+		extract     document.ExtractFileFactsFunc
+		wantPlanned map[string][]string
+	}{
+		{
+			name: "facts",
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title:         plclient.String("title"),
+					Correspondent: plclient.String("new correspondent"),
+					SetTags:       []string{"new tag"},
+				}}, nil
+			},
+			wantPlanned: map[string][]string{
+				"tag":           {"todo", "failed", "new tag"},
+				"correspondent": {"new correspondent"},
+			},
+		},
+		{
+			name: "failure",
+			metadata: plclient.DocumentMetadata{
+				OriginalSize: 100,
+			},
+			wantPlanned: map[string][]string{
+				"tag": {"todo", "failed"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
 			client := &fakeUpdaterClient{}
+			resolvers := objectresolver.NewMemObjectResolvers().WithoutCreate()
 
 			if tc.extract == nil {
-				tc.extract = func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+				tc.extract = func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
 					return nil, nil
 				}
 			}
@@ -181,30 +528,313 @@ func TestUpdater(t *testing.T) {
 				Logger:           zaptest.NewLogger(t),
 				Resolvers:        resolvers,
 				Client:           client,
-				Document:         &tc.doc,
+				Document:         &plclient.Document{},
 				Metadata:         &tc.metadata,
-				TodoTagName:      todoTag.Name,
-				FailedTagName:    failedTag.Name,
-				FileSizeMax:      fileSizeMax,
+				TodoTagName:      "todo",
+				FailedTagName:    "failed",
+				FileSizeMax:      10,
 				ExtractFileFacts: tc.extract,
-				CheckModified: func(context.Context) error {
-					return nil
+				CheckModified: func(_ context.Context, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
+					return patch, nil
 				},
+				DryRun: true,
 			})
+			if err != nil {
+				t.Fatalf("newUpdater() failed: %v", err)
+			}
+
+			if err := u.Do(ctx, false); err != nil {
+				t.Errorf("Do() failed: %v", err)
+			}
+
+			if len(client.patches) > 0 || len(client.notes) > 0 {
+				t.Errorf("Document modified in dry-run mode (patches %v, notes %v)", client.patches, client.notes)
+			}
+
+			if diff := cmp.Diff(tc.wantPlanned, resolvers.Planned(), cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b string) bool {
+				return a < b
+			})); diff != "" {
+				t.Errorf("Planned objects diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsPermanentError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{err: io.EOF},
+		{err: errDocumentTooLarge, want: true},
+		{err: fmt.Errorf("plugin %q: %w", "test", paperminer.Permanent(io.EOF)), want: true},
+		{err: fmt.Errorf("plugin %q: %w", "test", io.EOF)},
+	} {
+		if got := isPermanentError(tc.err); got != tc.want {
+			t.Errorf("isPermanentError(%q) returned %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
+
+type fakeSuggestionStore struct {
+	records map[int64]store.Suggestion
+}
+
+func (s *fakeSuggestionStore) Get(key, result any) error {
+	rec, ok := s.records[key.(int64)]
+	if !ok {
+		return bolthold.ErrNotFound
+	}
+
+	*result.(*store.Suggestion) = rec
+
+	return nil
+}
+
+func (s *fakeSuggestionStore) Upsert(key, data any) error {
+	if s.records == nil {
+		s.records = map[int64]store.Suggestion{}
+	}
+
+	s.records[key.(int64)] = data.(store.Suggestion)
+
+	return nil
+}
+
+func (s *fakeSuggestionStore) Delete(key, _ any) error {
+	if _, ok := s.records[key.(int64)]; !ok {
+		return bolthold.ErrNotFound
+	}
+
+	delete(s.records, key.(int64))
+
+	return nil
+}
+
+func TestUpdaterSuggest(t *testing.T) {
+	resolvers := objectresolver.NewMemObjectResolvers()
+
+	todoTag := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "todo")
+
+	for _, tc := range []struct {
+		name    string
+		suggest bool
+		facts   paperminer.Facts
+	}{
+		{
+			name:    "all facts",
+			suggest: true,
+			facts:   paperminer.Facts{Title: plclient.String("title")},
+		},
+		{
+			name:  "suggested by facter",
+			facts: paperminer.Facts{Suggest: true, Title: plclient.String("title")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			t.Cleanup(cancel)
+
+			client := &fakeUpdaterClient{}
+			st := &fakeSuggestionStore{}
 
+			u, err := newUpdater(ctx, updaterOptions{
+				Logger:    zaptest.NewLogger(t),
+				Resolvers: resolvers,
+				Client:    client,
+				Document: &plclient.Document{
+					ID:   7,
+					Tags: []int64{todoTag.ID},
+				},
+				Metadata:       &plclient.DocumentMetadata{OriginalChecksum: "abc"},
+				TodoTagName:    "todo",
+				FailedTagName:  "failed",
+				ReviewTagName:  "review",
+				ApproveTagName: "approve",
+				FileSizeMax:    1024,
+				ExtractFileFacts: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, document.File) (facter.FactsSlice, error) {
+					return facter.FactsSlice{&tc.facts}, nil
+				},
+				CheckModified: func(_ context.Context, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
+					return patch, nil
+				},
+				Suggest: tc.suggest,
+				Store:   st,
+			})
 			if err != nil {
-				t.Errorf("newUpdater() failed: %v", err)
+				t.Fatalf("newUpdater() failed: %v", err)
 			}
 
-			err = u.Do(ctx, tc.lastRetry)
+			if err := u.Do(ctx, false); err != nil {
+				t.Errorf("Do() failed: %v", err)
+			}
 
-			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("Error diff (-want +got):\n%s", diff)
+			reviewTag := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "review")
+
+			if diff := cmp.Diff([]map[string]any{{
+				"tags": []int64{reviewTag.ID},
+			}}, client.patches); diff != "" {
+				t.Errorf("Patches diff (-want +got):\n%s", diff)
 			}
 
-			if diff := cmp.Diff(tc.wantPatches, client.patches, cmpopts.EquateEmpty(), testutil.CmpSortInt64Slices); diff != "" {
+			if diff := cmp.Diff(map[int64]store.Suggestion{
+				7: {ID: 7, Facts: tc.facts, OriginalChecksum: "abc"},
+			}, st.records, cmpopts.IgnoreFields(store.Suggestion{}, "RecordCreated")); diff != "" {
+				t.Errorf("Stored suggestions diff (-want +got):\n%s", diff)
+			}
+
+			if len(client.notes) != 1 || !strings.HasPrefix(client.notes[0].Note, suggestionNoteHeader) {
+				t.Errorf("Got notes %+v, want one suggestion note", client.notes)
+			}
+
+			same := suggestionNote{facts: &tc.facts, originalChecksum: "abc", approveTag: "approve"}
+
+			if err := u.addSuggestionNote(ctx, same); err != nil {
+				t.Errorf("addSuggestionNote() failed: %v", err)
+			}
+
+			if len(client.notes) != 1 {
+				t.Errorf("Duplicate suggestion note added: %+v", client.notes)
+			}
+
+			changed := same
+			changed.originalChecksum = "def"
+
+			if err := u.addSuggestionNote(ctx, changed); err != nil {
+				t.Errorf("addSuggestionNote() failed: %v", err)
+			}
+
+			if len(client.notes) != 2 {
+				t.Errorf("Got notes %+v, want two suggestion notes", client.notes)
+			}
+
+			if _, err := resolvers.Tag.GetByName(ctx, "approve"); err != nil {
+				t.Errorf("Approval tag not created: %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdaterApprove(t *testing.T) {
+	resolvers := objectresolver.NewMemObjectResolvers()
+
+	todoTag := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "todo")
+	reviewTag := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "review")
+	approveTag := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "approve")
+	otherTag := objectresolver.MustGetOrCreateByName(t, resolvers.Tag, "other")
+
+	facts := paperminer.Facts{
+		Title:   plclient.String("approved title"),
+		SetTags: []string{"other"},
+	}
+
+	for _, tc := range []struct {
+		name        string
+		records     map[int64]store.Suggestion
+		notes       []plclient.DocumentNote
+		wantPatches []map[string]any
+	}{
+		{
+			name: "stored",
+			records: map[int64]store.Suggestion{
+				7: {ID: 7, Facts: facts, OriginalChecksum: "abc"},
+			},
+			wantPatches: []map[string]any{{
+				"title": "approved title",
+				"tags":  []int64{otherTag.ID},
+			}},
+		},
+		{
+			name: "from note",
+			notes: []plclient.DocumentNote{
+				{ID: 1, Note: suggestionNote{facts: &paperminer.Facts{Title: plclient.String("old")}, originalChecksum: "abc"}.String()},
+				{ID: 3, Note: suggestionNote{facts: &facts, originalChecksum: "abc"}.String()},
+				{ID: 2, Note: "comment"},
+				{ID: 4, Note: suggestionNoteHeader + "\nmalformed"},
+			},
+			wantPatches: []map[string]any{{
+				"title": "approved title",
+				"tags":  []int64{otherTag.ID},
+			}},
+		},
+		{
+			name: "note file changed",
+			notes: []plclient.DocumentNote{
+				{ID: 1, Note: suggestionNote{facts: &facts, originalChecksum: "old"}.String()},
+			},
+			wantPatches: []map[string]any{{
+				"tags": []int64{todoTag.ID},
+			}},
+		},
+		{
+			name: "malformed note",
+			notes: []plclient.DocumentNote{
+				{ID: 1, Note: suggestionNoteHeader + "\n\n" + suggestionNoteFactsPrefix + "{"},
+			},
+			wantPatches: []map[string]any{{
+				"tags": []int64{todoTag.ID},
+			}},
+		},
+		{
+			name: "file changed",
+			records: map[int64]store.Suggestion{
+				7: {ID: 7, Facts: facts, OriginalChecksum: "old"},
+			},
+			notes: []plclient.DocumentNote{
+				{ID: 1, Note: suggestionNote{facts: &facts}.String()},
+			},
+			wantPatches: []map[string]any{{
+				"tags": []int64{todoTag.ID},
+			}},
+		},
+		{
+			name: "missing",
+			wantPatches: []map[string]any{{
+				"tags": []int64{todoTag.ID},
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			t.Cleanup(cancel)
+
+			client := &fakeUpdaterClient{notes: tc.notes}
+			st := &fakeSuggestionStore{records: tc.records}
+
+			u, err := newUpdater(ctx, updaterOptions{
+				Logger:    zaptest.NewLogger(t),
+				Resolvers: resolvers,
+				Client:    client,
+				Document: &plclient.Document{
+					ID:    7,
+					Title: "title",
+					Tags:  []int64{reviewTag.ID, approveTag.ID},
+				},
+				Metadata:       &plclient.DocumentMetadata{OriginalChecksum: "abc"},
+				TodoTagName:    "todo",
+				FailedTagName:  "failed",
+				ReviewTagName:  "review",
+				ApproveTagName: "approve",
+				CheckModified: func(_ context.Context, patch *plclient.DocumentFields) (*plclient.DocumentFields, error) {
+					return patch, nil
+				},
+				Store: st,
+			})
+			if err != nil {
+				t.Fatalf("newUpdater() failed: %v", err)
+			}
+
+			if err := u.Approve(ctx); err != nil {
+				t.Errorf("Approve() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantPatches, client.patches); diff != "" {
 				t.Errorf("Patches diff (-want +got):\n%s", diff)
 			}
+
+			if len(st.records) != 0 {
+				t.Errorf("Suggestion not deleted: %+v", st.records)
+			}
 		})
 	}
 }
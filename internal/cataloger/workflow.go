@@ -2,20 +2,31 @@ package cataloger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"os"
+	"slices"
 	"strconv"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	plclient "github.com/hansmi/paperhooks/pkg/client"
 	"github.com/hansmi/paperminer/internal/document"
 	"github.com/hansmi/paperminer/internal/facter"
+	"github.com/hansmi/paperminer/internal/objectresolver"
 	"github.com/hansmi/paperminer/internal/poller"
 	wf "github.com/hansmi/paperminer/internal/workflow"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
+var errDocumentBusy = errors.New("document is being processed already")
+
 const minPollInterval = 10 * time.Second
 const maxPollInterval = time.Hour
 
@@ -25,6 +36,9 @@ type workflowClient interface {
 	updaterClient
 }
 
+var _ wf.NotificationReceiver = (*workflow)(nil)
+var _ wf.DocumentEventReceiver = (*workflow)(nil)
+
 type workflow struct {
 	env wf.Environment
 
@@ -32,22 +46,53 @@ type workflow struct {
 	pollInterval       time.Duration
 	tagNameTodo        string
 	tagNameFailed      string
+	tagNameReview      string
+	tagNameApprove     string
 	fileSizeMax        int64
 	retriesMax         int
 	factExtractTimeout time.Duration
+	allVariants        bool
+	suggest            bool
+	fieldProtection    string
+	dryRun             bool
 
-	facters *facter.Group
+	facters *facter.Reloader
 
 	notify chan struct{}
+
+	queueMu sync.Mutex
+
+	// IDs of consumed documents to process on the next poll.
+	queue []int64
+
+	// Whether all documents with the todo tag should be processed on the
+	// next poll.
+	scanRequested bool
+
+	// Time of the last poll processing all documents with the todo tag.
+	lastScan time.Time
+
+	busyMu sync.Mutex
+
+	// IDs of documents being processed.
+	busy map[int64]struct{}
+
+	userMu sync.Mutex
+
+	// ID of the Paperless user the client acts as. Zero until looked up.
+	userID int64
 }
 
 func New(ctx context.Context, env wf.Environment) (wf.Workflow, error) {
 	w := &workflow{
 		env:    env,
 		notify: make(chan struct{}, 1),
+		busy:   map[int64]struct{}{},
 	}
 	w.registerFlags(env.App())
 
+	env.AuthenticatedMux().Post("/documents/{id}/process", newProcessHandler(env.Logger(), w.processDocumentNow))
+
 	return w, nil
 }
 
@@ -58,9 +103,12 @@ func (w *workflow) registerFlags(app *kingpin.Application) {
 		return app.Flag("cataloger_"+name, help)
 	}
 
-	addFlag("list_facters", "List registered facters and exit.").
+	addFlag("list_facters", "List registered facters with their configuration and exit.").
 		BoolVar(&w.listFacters)
 
+	addFlag("dry_run", "Extract facts and log the resulting changes without modifying documents or creating objects. Documents are processed again on every poll and no attempts are recorded in the store.").
+		BoolVar(&w.dryRun)
+
 	addFlag("poll_interval",
 		fmt.Sprintf("Amount of time to wait between polls for documents (bounded to [%s..%s]).",
 			minPollInterval.String(), maxPollInterval.String())).
@@ -75,6 +123,21 @@ func (w *workflow) registerFlags(app *kingpin.Application) {
 		Default(fmt.Sprintf("%s:failed", programName)).
 		StringVar(&w.tagNameFailed)
 
+	addFlag("suggest", "Propose facts from all facters for review instead of applying them. See also --facter_suggest.").
+		BoolVar(&w.suggest)
+
+	addFlag("tag_review", "Tag to apply to documents with suggested facts.").
+		Default(fmt.Sprintf("%s:review", programName)).
+		StringVar(&w.tagNameReview)
+
+	addFlag("tag_approve", "Tag for users to apply suggested facts. Removed together with the review tag.").
+		Default(fmt.Sprintf("%s:approve", programName)).
+		StringVar(&w.tagNameApprove)
+
+	addFlag("field_protection", fmt.Sprintf("Policy for protecting document fields from being overwritten by facts unless a facter forces them. %q overwrites all fields; %q keeps fields changed by users according to the document history; %q keeps a title other than the original filename; %q keeps all fields.", fieldProtectionNone, fieldProtectionEdited, fieldProtectionNonDefault, fieldProtectionAll)).
+		Default(fieldProtectionEdited).
+		EnumVar(&w.fieldProtection, fieldProtectionPolicies...)
+
 	addFlag("retries_max", "Maximum number of retries for processing a document.").
 		Default("3").
 		IntVar(&w.retriesMax)
@@ -83,32 +146,165 @@ func (w *workflow) registerFlags(app *kingpin.Application) {
 		Default("5m").
 		DurationVar(&w.factExtractTimeout)
 
+	addFlag("extract_all_variants", "Extract facts from all document variants (content, archived, original) before selecting the best.").
+		BoolVar(&w.allVariants)
+
 	addFlag("file_size_max_bytes", "Ignore document files exceeding the given amount of bytes.").
 		Default(strconv.Itoa(10 * 1024 * 1024)).
 		Int64Var(&w.fileSizeMax)
 }
 
-func (w *workflow) NotifyPostConsume() {
+func (w *workflow) NotifyPostConsume(documentID int64) {
+	w.queueMu.Lock()
+	if documentID == 0 {
+		w.scanRequested = true
+	} else if !slices.Contains(w.queue, documentID) {
+		w.queue = append(w.queue, documentID)
+	}
+	w.queueMu.Unlock()
+
 	select {
 	case w.notify <- struct{}{}:
 	default:
 	}
 }
 
+func (w *workflow) NotifyDocumentEvent(event wf.DocumentEvent) {
+	switch event.Trigger {
+	case wf.TriggerAdded:
+		w.NotifyPostConsume(event.DocumentID)
+
+	default:
+		w.env.Logger().Debug("Ignoring document event",
+			zap.Int64("document_id", event.DocumentID),
+			zap.String("trigger", event.Trigger))
+	}
+}
+
+// takeQueue returns the queued document IDs and whether all documents with
+// the todo tag should be processed. The queue is emptied.
+func (w *workflow) takeQueue(now time.Time) ([]int64, bool) {
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
+
+	queue := w.queue
+
+	// Polls without queued documents are either due to the poll interval or
+	// a notification without document ID. The full scan also happens once
+	// per interval while documents keep being queued.
+	scan := w.scanRequested || len(queue) == 0 || now.Sub(w.lastScan) >= w.pollInterval
+
+	w.queue = nil
+	w.scanRequested = false
+
+	if scan {
+		w.lastScan = now
+	}
+
+	return queue, scan
+}
+
+// resolvers returns the object resolvers to use. In dry-run mode objects are
+// not created.
+func (w *workflow) resolvers() *objectresolver.ObjectResolvers {
+	if w.dryRun {
+		return w.env.Resolvers().WithoutCreate()
+	}
+
+	return w.env.Resolvers()
+}
+
+// suggesting returns whether any facts may be proposed for review.
+func (w *workflow) suggesting() bool {
+	return w.suggest || len(w.env.FacterOptions().Suggest) > 0
+}
+
+// currentUserID returns the ID of the Paperless user the client acts as.
+func (w *workflow) currentUserID(ctx context.Context) (int64, error) {
+	w.userMu.Lock()
+	defer w.userMu.Unlock()
+
+	if w.userID == 0 {
+		user, _, err := w.env.Client().GetCurrentUser(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("getting current user: %w", err)
+		}
+
+		w.userID = user.ID
+	}
+
+	return w.userID, nil
+}
+
+func (w *workflow) updaterOptions(ctx context.Context, logger *zap.Logger, t *task) (updaterOptions, error) {
+	var userID int64
+
+	// The user is only needed to tell own changes in the document history
+	// apart from edits.
+	if w.fieldProtection == fieldProtectionEdited {
+		var err error
+
+		if userID, err = w.currentUserID(ctx); err != nil {
+			return updaterOptions{}, err
+		}
+	}
+
+	return updaterOptions{
+		Logger:             logger,
+		Resolvers:          w.resolvers(),
+		TodoTagName:        w.tagNameTodo,
+		FailedTagName:      w.tagNameFailed,
+		ReviewTagName:      w.tagNameReview,
+		ApproveTagName:     w.tagNameApprove,
+		Client:             w.env.Client(),
+		Document:           t.doc,
+		Metadata:           t.metadata,
+		FileSizeMax:        w.fileSizeMax,
+		Attempt:            t.Attempt(),
+		ExtractTimeout:     w.factExtractTimeout,
+		ExtractAllVariants: w.allVariants,
+		CheckModified:      t.CheckModified,
+		Suggest:            w.suggest,
+		FieldProtection:    w.fieldProtection,
+		UserID:             userID,
+		Store:              w.env.Store(),
+		DryRun:             w.dryRun,
+	}, nil
+}
+
+// newProcessUpdater returns an updater extracting facts with the current
+// facters.
+func (w *workflow) newProcessUpdater(ctx context.Context, logger *zap.Logger, t *task) (*updater, error) {
+	var extractContentFacts updaterContentFactsFunc
+	var extractFileFacts document.ExtractFileFactsFunc
+
+	// Use the same facters for the whole document even if they're reloaded
+	// concurrently.
+	facters := w.facters.Group()
+
+	if facters.HasContentFacters() {
+		extractContentFacts = facters.ExtractContent
+	}
+
+	// Documents are only downloaded if there are facters requiring them.
+	if facters.HasDocumentFacters() {
+		extractFileFacts = document.MakeFileFactsExtractor(facters.Extract, document.DefaultFormats())
+	}
+
+	opts, err := w.updaterOptions(ctx, logger, t)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.FacterNames = facters.Names()
+	opts.ExtractContentFacts = extractContentFacts
+	opts.ExtractFileFacts = extractFileFacts
+
+	return newUpdater(ctx, opts)
+}
+
 func (w *workflow) processDocumentInner(ctx context.Context, logger *zap.Logger, t *task) error {
-	u, err := newUpdater(ctx, updaterOptions{
-		Logger:           logger,
-		Resolvers:        w.env.Resolvers(),
-		TodoTagName:      w.tagNameTodo,
-		FailedTagName:    w.tagNameFailed,
-		Client:           w.env.Client(),
-		Document:         t.doc,
-		Metadata:         t.metadata,
-		FileSizeMax:      w.fileSizeMax,
-		ExtractTimeout:   w.factExtractTimeout,
-		ExtractFileFacts: document.MakeFileFactsExtractor(w.facters.Extract),
-		CheckModified:    t.CheckModified,
-	})
+	u, err := w.newProcessUpdater(ctx, logger, t)
 	if err != nil {
 		return err
 	}
@@ -116,54 +312,214 @@ func (w *workflow) processDocumentInner(ctx context.Context, logger *zap.Logger,
 	return u.Do(ctx, t.RetryCount() >= w.retriesMax)
 }
 
-func (w *workflow) processDocument(ctx context.Context, logger *zap.Logger, doc *plclient.Document) error {
-	opts := taskOptions{
-		Logger: logger,
-		Store:  w.env.Store(),
-		Client: w.env.Client(),
+func (w *workflow) approveDocumentInner(ctx context.Context, logger *zap.Logger, t *task) error {
+	opts, err := w.updaterOptions(ctx, logger, t)
+	if err != nil {
+		return err
+	}
+
+	u, err := newUpdater(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return u.Approve(ctx)
+}
+
+// runTask processes a document. Documents being processed already, e.g. on
+// request, are skipped.
+func (w *workflow) runTask(ctx context.Context, logger *zap.Logger, doc *plclient.Document, fn func(context.Context, *zap.Logger, *task) error) error {
+	err := w.runTaskWithOptions(ctx, doc, taskOptions{Logger: logger}, fn)
+
+	if errors.Is(err, errDocumentBusy) {
+		logger.Info("Document is being processed already, skipping")
+		return nil
+	}
+
+	return err
+}
+
+// acquireDocument marks a document as being processed. False is returned if
+// it's in progress already.
+func (w *workflow) acquireDocument(id int64) bool {
+	w.busyMu.Lock()
+	defer w.busyMu.Unlock()
+
+	if _, ok := w.busy[id]; ok {
+		return false
 	}
 
-	return processDocument(ctx, doc, opts, w.processDocumentInner,
+	w.busy[id] = struct{}{}
+
+	return true
+}
+
+func (w *workflow) releaseDocument(id int64) {
+	w.busyMu.Lock()
+	defer w.busyMu.Unlock()
+
+	delete(w.busy, id)
+}
+
+// runTaskWithOptions processes a document and records the outcome unless in
+// dry-run mode. The store and client are set from the environment.
+// [errDocumentBusy] is returned if the document is being processed already.
+func (w *workflow) runTaskWithOptions(ctx context.Context, doc *plclient.Document, opts taskOptions, fn func(context.Context, *zap.Logger, *task) error) error {
+	if !w.acquireDocument(doc.ID) {
+		return errDocumentBusy
+	}
+
+	defer w.releaseDocument(doc.ID)
+
+	opts.Store = w.env.Store()
+	opts.Client = w.env.Client()
+	opts.DryRun = w.dryRun
+
+	return processDocument(ctx, doc, opts, fn,
 		func(count int) time.Duration {
 			return w.pollInterval * time.Duration(math.Pow(1.5, float64(1+count)))
 		},
 	)
 }
 
-func (w *workflow) processDocuments(ctx context.Context) error {
-	tag, err := w.env.Resolvers().Tag.GetOrCreateByName(ctx, w.tagNameTodo)
+func (w *workflow) processDocument(ctx context.Context, logger *zap.Logger, doc *plclient.Document) error {
+	return w.runTask(ctx, logger, doc, w.processDocumentInner)
+}
+
+func (w *workflow) approveDocument(ctx context.Context, logger *zap.Logger, doc *plclient.Document) error {
+	return w.runTask(ctx, logger, doc, w.approveDocumentInner)
+}
+
+// processQueued processes consumed documents by ID. Documents without the todo
+// tag are skipped.
+func (w *workflow) processQueued(ctx context.Context, ids []int64) error {
+	tag, err := w.resolvers().Tag.GetOrCreateByName(ctx, w.tagNameTodo)
 	if err != nil {
 		return err
 	}
 
-	return walkDocuments(ctx, w.env.Logger(), w.env.Client(), tag.ID, w.processDocument)
+	for _, id := range ids {
+		logger := w.env.Logger().With(zap.Int64("document_id", id))
+
+		doc, _, err := w.env.Client().GetDocument(ctx, id)
+		if err != nil {
+			logger.Error("Getting consumed document failed", zap.Error(err))
+			continue
+		}
+
+		if !slices.Contains(doc.Tags, tag.ID) {
+			logger.Info("Consumed document lacks todo tag, skipping")
+			continue
+		}
+
+		if err := w.processDocument(ctx, logger, doc); err != nil {
+			logger.Error("Error while processing document", zap.Error(err))
+		}
+	}
+
+	return nil
 }
 
-func (w *workflow) Validate(ctx context.Context) error {
-	facters, err := facter.GroupFromRegistry(w.env.PluginRegistry())
+func (w *workflow) processDocuments(ctx context.Context) error {
+	tag, err := w.resolvers().Tag.GetOrCreateByName(ctx, w.tagNameTodo)
+	if err != nil {
+		return err
+	}
+
+	if err := walkDocuments(ctx, w.env.Logger(), w.env.Client(), tag.ID, w.processDocument); err != nil {
+		return err
+	}
+
+	if !w.suggesting() {
+		return nil
+	}
+
+	approveTag, err := w.resolvers().Tag.GetOrCreateByName(ctx, w.tagNameApprove)
 	if err != nil {
 		return err
 	}
 
+	return walkDocuments(ctx, w.env.Logger(), w.env.Client(), approveTag.ID, w.approveDocument)
+}
+
+// writeFacterList writes a table describing all registered facters.
+func writeFacterList(w io.Writer, infos []facter.Info) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tENABLED\tPRIORITY\tSUGGEST")
+
+	for _, i := range infos {
+		priority := "-"
+
+		if i.Priority != nil {
+			priority = strconv.Itoa(*i.Priority)
+		}
+
+		fmt.Fprintf(tw, "%s\t%t\t%s\t%t\n", i.Name, i.Enabled, priority, i.Suggest)
+	}
+
+	return tw.Flush()
+}
+
+func (w *workflow) Validate(ctx context.Context) error {
 	if w.listFacters {
-		for _, i := range facters.Names() {
-			fmt.Println(i)
+		facters, err := facter.GroupFromRegistry(w.env.PluginRegistry(), w.env.FacterOptions())
+		if err != nil {
+			return err
+		}
+
+		if err := writeFacterList(os.Stdout, facters.Infos()); err != nil {
+			return err
 		}
 
 		return wf.ErrValidationEarlyExit
 	}
 
+	facters, err := facter.NewReloader(facter.ReloaderOptions{
+		Logger:   w.env.Logger(),
+		Registry: w.env.PluginRegistry(),
+		Options:  w.env.FacterOptions(),
+		Metrics:  prometheus.WrapRegistererWithPrefix(w.env.ProgramName()+"_", w.env.MetricsRegistry()),
+	})
+	if err != nil {
+		return err
+	}
+
 	w.facters = facters
 
 	return nil
 }
 
 func (w *workflow) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return w.facters.Run(ctx)
+	})
+	g.Go(func() error {
+		return w.poll(ctx)
+	})
+
+	return g.Wait()
+}
+
+func (w *workflow) poll(ctx context.Context) error {
 	logger := w.env.Logger()
 
 	return poller.Poll(ctx, poller.Options{
 		Logger: logger,
 		Poll: func(ctx context.Context) {
+			queue, scan := w.takeQueue(time.Now())
+
+			if len(queue) > 0 {
+				if err := w.processQueued(ctx, queue); err != nil {
+					logger.Error("Processing consumed documents failed", zap.Error(err))
+				}
+			}
+
+			if !scan {
+				return
+			}
+
 			if err := w.processDocuments(ctx); err != nil {
 				logger.Error("Processing documents failed", zap.Error(err))
 			}
@@ -0,0 +1,134 @@
+package cataloger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWorkflowQueue(t *testing.T) {
+	start := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	w := &workflow{
+		pollInterval: time.Minute,
+		notify:       make(chan struct{}, 1),
+		lastScan:     start,
+	}
+
+	for _, tc := range []struct {
+		name      string
+		elapsed   time.Duration
+		notify    []int64
+		want      []int64
+		wantScan  bool
+		wantWakes int
+	}{
+		{
+			name:     "poll interval",
+			elapsed:  time.Minute,
+			wantScan: true,
+		},
+		{
+			name:      "documents",
+			elapsed:   time.Minute + time.Second,
+			notify:    []int64{3, 1, 3},
+			want:      []int64{3, 1},
+			wantWakes: 1,
+		},
+		{
+			name:      "without ID",
+			elapsed:   time.Minute + 2*time.Second,
+			notify:    []int64{0},
+			wantScan:  true,
+			wantWakes: 1,
+		},
+		{
+			name:      "mixed",
+			elapsed:   time.Minute + 3*time.Second,
+			notify:    []int64{7, 0},
+			want:      []int64{7},
+			wantScan:  true,
+			wantWakes: 1,
+		},
+		{
+			name:      "documents before interval",
+			elapsed:   2*time.Minute + 2*time.Second,
+			notify:    []int64{8},
+			want:      []int64{8},
+			wantWakes: 1,
+		},
+		{
+			name:      "documents after interval",
+			elapsed:   2*time.Minute + 3*time.Second,
+			notify:    []int64{9},
+			want:      []int64{9},
+			wantScan:  true,
+			wantWakes: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, id := range tc.notify {
+				w.NotifyPostConsume(id)
+			}
+
+			if got := len(w.notify); got != tc.wantWakes {
+				t.Errorf("Got %d wake-ups, want %d", got, tc.wantWakes)
+			}
+
+			for len(w.notify) > 0 {
+				<-w.notify
+			}
+
+			queue, scan := w.takeQueue(start.Add(tc.elapsed))
+
+			if diff := cmp.Diff(tc.want, queue); diff != "" {
+				t.Errorf("Queue diff (-want +got):\n%s", diff)
+			}
+
+			if scan != tc.wantScan {
+				t.Errorf("Scan is %t, want %t", scan, tc.wantScan)
+			}
+		})
+	}
+}
+
+func TestWorkflowBusyDocument(t *testing.T) {
+	w := &workflow{
+		busy: map[int64]struct{}{},
+	}
+
+	if !w.acquireDocument(5) {
+		t.Fatalf("acquireDocument() failed")
+	}
+
+	if w.acquireDocument(5) {
+		t.Errorf("acquireDocument() succeeded for busy document")
+	}
+
+	fn := func(context.Context, *zap.Logger, *task) error {
+		t.Errorf("Busy document processed")
+		return nil
+	}
+
+	doc := &plclient.Document{ID: 5}
+
+	if err := w.runTaskWithOptions(context.Background(), doc, taskOptions{}, fn); !errors.Is(err, errDocumentBusy) {
+		t.Errorf("runTaskWithOptions() returned %v, want %v", err, errDocumentBusy)
+	}
+
+	if err := w.runTask(context.Background(), zaptest.NewLogger(t), doc, fn); err != nil {
+		t.Errorf("runTask() failed: %v", err)
+	}
+
+	w.releaseDocument(5)
+
+	if !w.acquireDocument(5) {
+		t.Errorf("acquireDocument() failed after release")
+	}
+}
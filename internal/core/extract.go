@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/document"
+	"github.com/hansmi/paperminer/internal/facter"
+	"go.uber.org/zap"
+)
+
+var errNothingMatched = errors.New("no facts extracted from any file")
+
+// extractPaths expands directories to the regular files they contain.
+// Explicitly named files are kept in the given order.
+func extractPaths(paths []string) ([]string, error) {
+	var result []string
+
+	for _, path := range paths {
+		var found []string
+
+		if err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.Type().IsRegular() {
+				found = append(found, p)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+
+		sort.Strings(found)
+
+		result = append(result, found...)
+	}
+
+	return result, nil
+}
+
+type extractResult struct {
+	Path  string            `json:"path"`
+	Error string            `json:"error,omitempty"`
+	All   facter.FactsSlice `json:"all,omitempty"`
+	Best  *paperminer.Facts `json:"best,omitempty"`
+}
+
+type extractOptions struct {
+	logger  *zap.Logger
+	out     io.Writer
+	paths   []string
+	extract document.ExtractFileFactsFunc
+}
+
+// makeExtractDocFacts returns a function running the document facters on
+// a parsed file and the content facters on its text.
+func makeExtractDocFacts(facters *facter.Group) document.ExtractDocFactsFunc {
+	return func(ctx context.Context, opts paperminer.DocumentFacterOptions) (facter.FactsSlice, error) {
+		var result facter.FactsSlice
+
+		if facters.HasContentFacters() {
+			content, err := document.Text(ctx, opts)
+			if err != nil {
+				return nil, fmt.Errorf("text content: %w", err)
+			}
+
+			all, err := facters.ExtractContent(ctx, opts.Logger, opts.Info, content)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, all...)
+		}
+
+		all, err := facters.Extract(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(result, all...), nil
+	}
+}
+
+// runExtract extracts facts from local files and writes one JSON object per
+// file to the output. An error is returned if no file produced any facts.
+func runExtract(ctx context.Context, o extractOptions) error {
+	paths, err := extractPaths(o.paths)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(o.out)
+	enc.SetIndent("", "  ")
+
+	matched := false
+
+	for _, path := range paths {
+		logger := o.logger.With(zap.String("path", path))
+		result := extractResult{Path: path}
+
+		info := &paperminer.DocumentInfo{
+			Title:            strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			OriginalFileName: filepath.Base(path),
+		}
+
+		if all, err := o.extract(ctx, logger, info, document.File{
+			Path:     path,
+			Filename: filepath.Base(path),
+		}); err != nil {
+			result.Error = err.Error()
+		} else {
+			for _, f := range all {
+				if !(f == nil || f.IsEmpty()) {
+					result.All = append(result.All, f)
+				}
+			}
+
+			if len(result.All) > 0 {
+				if best, err := result.All.Best(); err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Best = best
+					matched = true
+				}
+			}
+		}
+
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("writing result for %q: %w", path, err)
+		}
+	}
+
+	if !matched {
+		return errNothingMatched
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/document"
+	"github.com/hansmi/paperminer/internal/facter"
+	"github.com/hansmi/paperminer/internal/ref"
+	"github.com/hansmi/paperminer/internal/testutil"
+	"github.com/hansmi/staticplug"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExtractPaths(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	for _, name := range []string{"b.pdf", "a.pdf", "sub/c.pdf"} {
+		path := filepath.Join(tmpdir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		testutil.MustWriteFileString(t, path, "")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr error
+	}{
+		{name: "empty"},
+		{
+			name:  "file",
+			paths: []string{filepath.Join(tmpdir, "b.pdf")},
+			want:  []string{filepath.Join(tmpdir, "b.pdf")},
+		},
+		{
+			name:  "directory",
+			paths: []string{tmpdir},
+			want: []string{
+				filepath.Join(tmpdir, "a.pdf"),
+				filepath.Join(tmpdir, "b.pdf"),
+				filepath.Join(tmpdir, "sub", "c.pdf"),
+			},
+		},
+		{
+			name:    "missing",
+			paths:   []string{filepath.Join(tmpdir, "missing")},
+			wantErr: os.ErrNotExist,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := extractPaths(tc.paths)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Paths diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunExtract(t *testing.T) {
+	errTest := errors.New("test error")
+
+	tmpdir := t.TempDir()
+	invoice := testutil.MustWriteFileString(t, filepath.Join(tmpdir, "invoice.pdf"), "")
+	letter := testutil.MustWriteFileString(t, filepath.Join(tmpdir, "letter.pdf"), "")
+	broken := testutil.MustWriteFileString(t, filepath.Join(tmpdir, "broken.pdf"), "")
+
+	extract := func(_ context.Context, _ *zap.Logger, _ *paperminer.DocumentInfo, file document.File) (facter.FactsSlice, error) {
+		switch file.Path {
+		case invoice:
+			return facter.FactsSlice{
+				{Reporter: ref.Ref("a"), Title: plclient.String("Invoice")},
+				{Reporter: ref.Ref("b"), Correspondent: plclient.String("ACME")},
+				{Reporter: ref.Ref("empty")},
+			}, nil
+		case broken:
+			return nil, errTest
+		}
+
+		return nil, nil
+	}
+
+	for _, tc := range []struct {
+		name    string
+		paths   []string
+		want    []extractResult
+		wantErr error
+	}{
+		{
+			name:    "no match",
+			paths:   []string{letter, broken},
+			wantErr: errNothingMatched,
+			want: []extractResult{
+				{Path: letter},
+				{Path: broken, Error: errTest.Error()},
+			},
+		},
+		{
+			name:  "directory",
+			paths: []string{tmpdir},
+			want: []extractResult{
+				{Path: broken, Error: errTest.Error()},
+				{
+					Path: invoice,
+					All: facter.FactsSlice{
+						{Reporter: ref.Ref("a"), Title: plclient.String("Invoice")},
+						{Reporter: ref.Ref("b"), Correspondent: plclient.String("ACME")},
+					},
+					Best: &paperminer.Facts{
+						Reporter:      ref.Ref("a, b"),
+						Title:         plclient.String("Invoice"),
+						Correspondent: plclient.String("ACME"),
+					},
+				},
+				{Path: letter},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			t.Cleanup(cancel)
+
+			var buf strings.Builder
+
+			err := runExtract(ctx, extractOptions{
+				logger:  zaptest.NewLogger(t),
+				out:     &buf,
+				paths:   tc.paths,
+				extract: extract,
+			})
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			var got []extractResult
+
+			dec := json.NewDecoder(strings.NewReader(buf.String()))
+
+			for dec.More() {
+				var result extractResult
+
+				if err := dec.Decode(&result); err != nil {
+					t.Fatalf("Decoding output failed: %v", err)
+				}
+
+				got = append(got, result)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Result diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakeContentFacter struct{}
+
+func (*fakeContentFacter) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: "content",
+		New: func() (staticplug.Plugin, error) {
+			return &fakeContentFacter{}, nil
+		},
+	}
+}
+
+func (*fakeContentFacter) ContentFacts(_ context.Context, opts paperminer.ContentFacterOptions) (*paperminer.Facts, error) {
+	if before, _, ok := strings.Cut(opts.Content, "\n"); ok {
+		return &paperminer.Facts{Title: ref.Ref(before)}, nil
+	}
+
+	return nil, nil
+}
+
+func TestProgramRunExtractContent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "letter.txt"), "Letter from ACME\nDear customer")
+
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeContentFacter{})
+
+	var buf strings.Builder
+
+	p := &Program{
+		logger:         zaptest.NewLogger(t),
+		pluginRegistry: reg,
+		extractPaths:   []string{path},
+		stdout:         &buf,
+	}
+
+	if err := p.runExtract(ctx); err != nil {
+		t.Errorf("runExtract() failed: %v", err)
+	}
+
+	var got extractResult
+
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("Decoding output failed: %v", err)
+	}
+
+	want := extractResult{
+		Path: path,
+		All: facter.FactsSlice{
+			{Reporter: ref.Ref("content"), Title: ref.Ref("Letter from ACME")},
+		},
+		Best: &paperminer.Facts{
+			Reporter: ref.Ref("content"),
+			Title:    ref.Ref("Letter from ACME"),
+		},
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Result diff (-want +got):\n%s", diff)
+	}
+}
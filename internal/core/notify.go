@@ -0,0 +1,62 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const notifyBodySizeMax = 64 * 1024
+
+type postConsumeRequest struct {
+	DocumentID int64 `json:"document_id"`
+}
+
+// postConsumeDocumentID returns the ID of the consumed document given in
+// a post-consume notification, either as a JSON body or as the "document_id"
+// query or form parameter. Zero is returned if no ID was given.
+func postConsumeDocumentID(r *http.Request) (int64, error) {
+	var id int64
+
+	r.Body = http.MaxBytesReader(nil, r.Body, notifyBodySizeMax)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		var req postConsumeRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return 0, fmt.Errorf("%w: decoding request: %v", os.ErrInvalid, err)
+		}
+
+		id = req.DocumentID
+	}
+
+	if value := r.FormValue("document_id"); id == 0 && value != "" {
+		var err error
+
+		if id, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, fmt.Errorf("%w: document ID: %v", os.ErrInvalid, err)
+		}
+	}
+
+	if id < 0 {
+		return 0, fmt.Errorf("%w: negative document ID %d", os.ErrInvalid, id)
+	}
+
+	return id, nil
+}
+
+func (p *Program) handlePostConsume(w http.ResponseWriter, r *http.Request) {
+	id, err := postConsumeDocumentID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.notifyPostConsume(id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestPostConsumeDocumentID(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		want        int64
+		wantErr     error
+	}{
+		{
+			name:   "empty",
+			target: "/notify/post-consume",
+		},
+		{
+			name:   "query",
+			target: "/notify/post-consume?document_id=123",
+			want:   123,
+		},
+		{
+			name:        "form",
+			target:      "/notify/post-consume",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "document_id=45",
+			want:        45,
+		},
+		{
+			name:        "json",
+			target:      "/notify/post-consume",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"document_id": 678}`,
+			want:        678,
+		},
+		{
+			name:        "empty json",
+			target:      "/notify/post-consume?document_id=9",
+			contentType: "application/json",
+			want:        9,
+		},
+		{
+			name:   "ignored body",
+			target: "/notify/post-consume",
+			body:   `{"document_id": 678}`,
+		},
+		{
+			name:        "invalid json",
+			target:      "/notify/post-consume",
+			contentType: "application/json",
+			body:        `{`,
+			wantErr:     os.ErrInvalid,
+		},
+		{
+			name:    "invalid query",
+			target:  "/notify/post-consume?document_id=abc",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "negative",
+			target:  "/notify/post-consume?document_id=-1",
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(tc.body))
+
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+
+			got, err := postConsumeDocumentID(r)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if got != tc.want {
+				t.Errorf("postConsumeDocumentID() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
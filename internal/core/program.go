@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/netip"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-chi/chi/v5"
@@ -17,6 +19,8 @@ import (
 	"github.com/hansmi/paperhooks/pkg/kpflag"
 	"github.com/hansmi/paperminer"
 	"github.com/hansmi/paperminer/internal/cataloger"
+	"github.com/hansmi/paperminer/internal/document"
+	"github.com/hansmi/paperminer/internal/facter"
 	"github.com/hansmi/paperminer/internal/httpsrv"
 	"github.com/hansmi/paperminer/internal/objectresolver"
 	"github.com/hansmi/paperminer/internal/workflow"
@@ -53,9 +57,16 @@ type Program struct {
 	mux                     *chi.Mux
 
 	storeDir          string
+	storeLockTimeout  time.Duration
 	listenAddress     string
+	webhookSecret     string
 	clientFlags       plclient.Flags
 	objectPermissions objectresolver.NamedObjectPermissions
+	facterOptions     facter.Options
+
+	command      string
+	extractPaths []string
+	stdout       io.Writer
 
 	workflowEnvBase *workflowEnvBase
 	workflows       []workflow.Workflow
@@ -68,6 +79,7 @@ func NewProgram(ctx context.Context, logger *zap.Logger, app *kingpin.Applicatio
 
 		pluginRegistry:  paperminer.GlobalPluginRegistry(),
 		metricsRegistry: prometheus.NewPedanticRegistry(),
+		stdout:          os.Stdout,
 	}
 	p.registerFlags(app)
 	p.setupMux()
@@ -93,9 +105,40 @@ func (p *Program) registerFlags(app *kingpin.Application) {
 		Default(net.JoinHostPort(netip.IPv6Loopback().String(), "0")).
 		StringVar(&p.listenAddress)
 
+	app.Flag("webhook_secret", "Shared secret for webhooks from Paperless workflows, sent as a bearer token or used for an HMAC-SHA256 signature of the body. Webhooks are rejected if empty.").
+		PlaceHolder("SECRET").
+		StringVar(&p.webhookSecret)
+
+	app.Flag("store_dir", fmt.Sprintf("Directory for a persistent store reused across restarts. The bolt database is kept in a file named %q within. A temporary store is used if empty.", persistentStoreFileName)).
+		PlaceHolder("DIR").
+		StringVar(&p.storeDir)
+
+	app.Flag("store_lock_timeout", "Maximum amount of time to wait for the exclusive lock on a persistent store. Must be positive.").
+		Default("10s").
+		DurationVar(&p.storeLockTimeout)
+
 	kpflag.RegisterClient(app, &p.clientFlags)
 
 	p.objectPermissions.RegisterFlags(app)
+	p.facterOptions.RegisterFlags(app)
+
+	app.Command("run", "Connect to Paperless and run all workflows.").
+		Default().
+		Action(p.selectCommand)
+
+	extractCmd := app.Command("extract", "Run the registered facters on local files and print the facts as JSON. No Paperless connection is needed.").
+		Action(p.selectCommand)
+	extractCmd.Arg("path", "Files or directories to process.").
+		Required().
+		ExistingFilesOrDirsVar(&p.extractPaths)
+}
+
+func (p *Program) selectCommand(pc *kingpin.ParseContext) error {
+	if pc.SelectedCommand != nil {
+		p.command = pc.SelectedCommand.FullCommand()
+	}
+
+	return nil
 }
 
 func (p *Program) setupMux() {
@@ -105,10 +148,8 @@ func (p *Program) setupMux() {
 			Registry: p.metricsRegistry,
 		}))
 
-	p.mux.Post("/notify/post-consume", func(w http.ResponseWriter, r *http.Request) {
-		p.notifyPostConsume()
-		w.WriteHeader(http.StatusNoContent)
-	})
+	p.mux.Post("/notify/post-consume", p.handlePostConsume)
+	p.mux.With(p.requireWebhookSecret).Post("/webhooks/paperless", p.handleWebhook)
 }
 
 func (p *Program) setupWorkflows(ctx context.Context) ([]workflow.Workflow, error) {
@@ -129,15 +170,33 @@ func (p *Program) setupWorkflows(ctx context.Context) ([]workflow.Workflow, erro
 	return result, nil
 }
 
-func (p *Program) notifyPostConsume() {
+func (p *Program) notifyPostConsume(documentID int64) {
 	for _, wf := range p.workflows {
 		if nr, ok := wf.(workflow.NotificationReceiver); ok && nr != nil {
-			nr.NotifyPostConsume()
+			nr.NotifyPostConsume(documentID)
 		}
 	}
 }
 
+func (p *Program) runExtract(ctx context.Context) error {
+	facters, err := facter.GroupFromRegistry(p.pluginRegistry, p.facterOptions)
+	if err != nil {
+		return err
+	}
+
+	return runExtract(ctx, extractOptions{
+		logger:  p.logger,
+		out:     p.stdout,
+		paths:   p.extractPaths,
+		extract: document.MakeFileFactsExtractor(makeExtractDocFacts(facters), document.DefaultFormats()),
+	})
+}
+
 func (p *Program) Run(ctx context.Context) (err error) {
+	if p.command == "extract" {
+		return p.runExtract(ctx)
+	}
+
 	p.metricsRegistry.MustRegister(
 		collectors.NewBuildInfoCollector(),
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
@@ -158,7 +217,7 @@ func (p *Program) Run(ctx context.Context) (err error) {
 		return err
 	}
 
-	s, storeCleanup, err := openDefaultStore(p.storeDir)
+	s, storeCleanup, err := openStore(p.storeDir, p.storeLockTimeout)
 	if err != nil {
 		return err
 	}
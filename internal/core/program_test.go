@@ -11,6 +11,8 @@ import (
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	plclient "github.com/hansmi/paperhooks/pkg/client"
 	"go.uber.org/zap/zaptest"
 )
@@ -27,9 +29,15 @@ func TestNewProgram(t *testing.T) {
 
 	for _, name := range []string{
 		"cataloger_poll_interval",
+		"facter_disable",
+		"facter_enable",
+		"facter_priority",
 		"listen_address",
 		"object_default_owner_name",
 		"paperless_server_timezone",
+		"store_dir",
+		"store_lock_timeout",
+		"webhook_secret",
 	} {
 		if got := app.GetFlag(name); got == nil {
 			t.Errorf("Missing flag %q", name)
@@ -115,3 +123,54 @@ func TestProgramRun(t *testing.T) {
 		})
 	}
 }
+
+func TestProgramCommand(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	for _, tc := range []struct {
+		name      string
+		args      []string
+		want      string
+		wantPaths []string
+	}{
+		{
+			name: "default",
+			want: "run",
+		},
+		{
+			name: "run",
+			args: []string{"run"},
+			want: "run",
+		},
+		{
+			name:      "extract",
+			args:      []string{"extract", tmpdir},
+			want:      "extract",
+			wantPaths: []string{tmpdir},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			t.Cleanup(cancel)
+
+			app := kingpin.New("test", "")
+
+			p, err := NewProgram(ctx, zaptest.NewLogger(t), app)
+			if err != nil {
+				t.Fatalf("NewProgram() failed: %v", err)
+			}
+
+			if _, err := app.Parse(tc.args); err != nil {
+				t.Errorf("Parsing arguments failed: %v", err)
+			}
+
+			if p.command != tc.want {
+				t.Errorf("Selected command %q, want %q", p.command, tc.want)
+			}
+
+			if diff := cmp.Diff(tc.wantPaths, p.extractPaths, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Paths diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
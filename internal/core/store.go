@@ -5,12 +5,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/hansmi/paperminer/internal/store"
 	"github.com/timshannon/bolthold"
+	"go.etcd.io/bbolt"
 	"go.uber.org/multierr"
 )
 
+// Name of the store file within a persistent store directory.
+const persistentStoreFileName = "store.bin"
+
+func ensureStoreDir(dir string) error {
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0o0700); err != nil {
+			return fmt.Errorf("creating store directory: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // openDefaultStore creates a new store in a temporary location. The returned
 // cleanup function should be called when the store is closed and no longer
 // used (usually on process termination).
@@ -21,10 +36,8 @@ func openDefaultStore(dir string) (_ *bolthold.Store, cleanup func() error, err
 		}
 	}
 
-	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
-		if err := os.MkdirAll(dir, 0o0700); err != nil {
-			return nil, nil, fmt.Errorf("creating store directory: %w", err)
-		}
+	if err := ensureStoreDir(dir); err != nil {
+		return nil, nil, err
 	}
 
 	tmpdir, err := os.MkdirTemp(dir, "paperminer-*")
@@ -47,3 +60,39 @@ func openDefaultStore(dir string) (_ *bolthold.Store, cleanup func() error, err
 		return nil
 	}, nil
 }
+
+// openPersistentStore opens a store file in the given directory, creating it
+// if necessary. The file is reused across restarts. An exclusive lock is held
+// while the store is open; waiting for it is given up after lockTimeout. The
+// returned cleanup function closes the store. The timeout must be positive as
+// bbolt would otherwise wait for the lock indefinitely.
+func openPersistentStore(dir string, lockTimeout time.Duration) (*bolthold.Store, func() error, error) {
+	if lockTimeout <= 0 {
+		return nil, nil, fmt.Errorf("%w: store lock timeout must be positive, got %v", os.ErrInvalid, lockTimeout)
+	}
+
+	if err := ensureStoreDir(dir); err != nil {
+		return nil, nil, err
+	}
+
+	path := filepath.Join(dir, persistentStoreFileName)
+
+	s, err := store.Open(path, lockTimeout)
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, nil, fmt.Errorf("store %q is locked, another process may be using it: %w", path, err)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	return s, s.Close, nil
+}
+
+// openStore opens a persistent store if a directory is given and
+// a temporary store otherwise.
+func openStore(dir string, lockTimeout time.Duration) (*bolthold.Store, func() error, error) {
+	if dir == "" {
+		return openDefaultStore("")
+	}
+
+	return openPersistentStore(dir, lockTimeout)
+}
@@ -1,12 +1,16 @@
 package core
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer/internal/store"
+	"go.etcd.io/bbolt"
 )
 
 func TestOpenDefaultStore(t *testing.T) {
@@ -49,3 +53,50 @@ func TestOpenDefaultStore(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenPersistentStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		if _, _, err := openPersistentStore(dir, timeout); !errors.Is(err, os.ErrInvalid) {
+			t.Errorf("openPersistentStore(%v) didn't reject timeout: %v", timeout, err)
+		}
+	}
+
+	first, cleanup, err := openPersistentStore(dir, time.Second)
+	if err != nil {
+		t.Fatalf("openPersistentStore() failed: %v", err)
+	}
+
+	if err := first.Insert("key", store.DocumentTask{ID: 123}); err != nil {
+		t.Errorf("Insert() failed: %v", err)
+	}
+
+	// Store is locked while open.
+	if _, _, err := openPersistentStore(dir, time.Millisecond); !errors.Is(err, bbolt.ErrTimeout) {
+		t.Errorf("openPersistentStore() didn't fail with timeout: %v", err)
+	}
+
+	if err := cleanup(); err != nil {
+		t.Errorf("cleanup() failed: %v", err)
+	}
+
+	second, cleanup, err := openPersistentStore(dir, time.Second)
+	if err != nil {
+		t.Fatalf("openPersistentStore() failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := cleanup(); err != nil {
+			t.Errorf("cleanup() failed: %v", err)
+		}
+	})
+
+	var got store.DocumentTask
+
+	if err := second.Get("key", &got); err != nil {
+		t.Errorf("Get() failed: %v", err)
+	} else if diff := cmp.Diff(store.DocumentTask{ID: 123}, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Record diff (-want +got):\n%s", diff)
+	}
+}
@@ -37,17 +37,22 @@ func (p *storePruner) Validate(ctx context.Context) error {
 
 func (p *storePruner) Run(ctx context.Context) error {
 	const deleteUpdatedAfter = 24 * time.Hour
+	const deleteSuggestionsAfter = 90 * 24 * time.Hour
 
 	logger := p.env.Logger()
 
 	return poller.Poll(ctx, poller.Options{
 		Logger: logger,
 		Poll: func(ctx context.Context) {
-			deleteUpdatedBefore := time.Now().Add(-deleteUpdatedAfter)
+			now := time.Now()
 
-			if err := store.Prune(ctx, logger, p.env.Store(), deleteUpdatedBefore); err != nil {
+			if err := store.Prune(ctx, logger, p.env.Store(), now.Add(-deleteUpdatedAfter)); err != nil {
 				logger.Error("Store pruning failed", zap.Error(err))
 			}
+
+			if err := store.PruneSuggestions(ctx, logger, p.env.Store(), now.Add(-deleteSuggestionsAfter)); err != nil {
+				logger.Error("Pruning suggestions failed", zap.Error(err))
+			}
 		},
 		MinDelay:  p.minDelay,
 		NextDelay: p.nextDelay,
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hansmi/paperminer/internal/workflow"
+	"go.uber.org/zap"
+)
+
+const webhookSignatureHeader = "X-Hub-Signature-256"
+
+var errWebhookUnauthorized = errors.New("missing or invalid webhook secret")
+
+// Document URL as given by the "doc_url" placeholder in Paperless workflows.
+var webhookDocURLRe = regexp.MustCompile(`/documents/(\d+)/?(?:details/?)?$`)
+
+// webhookTriggers maps trigger names accepted in webhook payloads to event
+// triggers.
+var webhookTriggers = map[string]string{
+	"":                 workflow.TriggerAdded,
+	"added":            workflow.TriggerAdded,
+	"consumed":         workflow.TriggerAdded,
+	"document_added":   workflow.TriggerAdded,
+	"updated":          workflow.TriggerUpdated,
+	"document_updated": workflow.TriggerUpdated,
+}
+
+// checkWebhookAuth verifies that a request carries the shared secret, either
+// as a bearer token or as an HMAC-SHA256 signature of the body in the
+// X-Hub-Signature-256 header ("sha256=" followed by the hex digest).
+func checkWebhookAuth(r *http.Request, body []byte, secret string) error {
+	if value := r.Header.Get(webhookSignatureHeader); value != "" {
+		got, err := hex.DecodeString(strings.TrimPrefix(value, "sha256="))
+		if err != nil {
+			return fmt.Errorf("%w: decoding signature: %v", errWebhookUnauthorized, err)
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return fmt.Errorf("%w: signature mismatch", errWebhookUnauthorized)
+		}
+
+		return nil
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+		subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(secret)) == 1 {
+		return nil
+	}
+
+	return errWebhookUnauthorized
+}
+
+// webhookValues returns the string values of a webhook payload, either
+// a JSON object or form parameters. Paperless renders placeholders as
+// strings; JSON numbers are accepted as well.
+func webhookValues(r *http.Request, body []byte) (map[string]string, error) {
+	result := map[string]string{}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		var payload map[string]any
+
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("%w: decoding payload: %v", os.ErrInvalid, err)
+		}
+
+		for key, value := range payload {
+			switch v := value.(type) {
+			case string:
+				result[key] = v
+			case float64:
+				result[key] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+
+		return result, nil
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("%w: parsing form: %v", os.ErrInvalid, err)
+	}
+
+	for key := range r.Form {
+		result[key] = r.Form.Get(key)
+	}
+
+	return result, nil
+}
+
+// parseWebhookEvent extracts the document ID and trigger from the values of
+// a webhook payload. The ID is taken from "document_id" or, if missing, the
+// document URL in "doc_url". A missing trigger is treated as an added
+// document.
+func parseWebhookEvent(values map[string]string) (workflow.DocumentEvent, error) {
+	var event workflow.DocumentEvent
+
+	idValue := strings.TrimSpace(values["document_id"])
+
+	if idValue == "" {
+		if m := webhookDocURLRe.FindStringSubmatch(strings.TrimSpace(values["doc_url"])); m != nil {
+			idValue = m[1]
+		}
+	}
+
+	if idValue == "" {
+		return event, fmt.Errorf("%w: missing document ID", os.ErrInvalid)
+	}
+
+	id, err := strconv.ParseInt(idValue, 10, 64)
+	if err != nil || id < 1 {
+		return event, fmt.Errorf("%w: invalid document ID %q", os.ErrInvalid, idValue)
+	}
+
+	trigger, ok := webhookTriggers[strings.ToLower(strings.TrimSpace(values["trigger"]))]
+	if !ok {
+		return event, fmt.Errorf("%w: unknown trigger %q", os.ErrInvalid, values["trigger"])
+	}
+
+	event.DocumentID = id
+	event.Trigger = trigger
+
+	return event, nil
+}
+
+// requireWebhookSecret is a middleware rejecting requests without the webhook
+// secret (see [checkWebhookAuth]). All requests are rejected if no secret is
+// configured.
+func (p *Program) requireWebhookSecret(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.webhookSecret == "" {
+			http.Error(w, "webhooks are not configured", http.StatusNotFound)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, notifyBodySizeMax))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := checkWebhookAuth(r, body, p.webhookSecret); err != nil {
+			p.logger.Warn("Rejected request", zap.String("path", r.URL.Path), zap.Error(err))
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p *Program) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	values, err := webhookValues(r, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := parseWebhookEvent(values)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.logger.Info("Received webhook",
+		zap.Int64("document_id", event.DocumentID),
+		zap.String("trigger", event.Trigger))
+
+	p.notifyDocumentEvent(event)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *Program) notifyDocumentEvent(event workflow.DocumentEvent) {
+	for _, wf := range p.workflows {
+		if r, ok := wf.(workflow.DocumentEventReceiver); ok && r != nil {
+			r.NotifyDocumentEvent(event)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer/internal/workflow"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeEventWorkflow struct {
+	events []workflow.DocumentEvent
+}
+
+func (*fakeEventWorkflow) Validate(context.Context) error { return nil }
+func (*fakeEventWorkflow) Run(context.Context) error      { return nil }
+
+func (w *fakeEventWorkflow) NotifyDocumentEvent(event workflow.DocumentEvent) {
+	w.events = append(w.events, event)
+}
+
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		values  map[string]string
+		want    workflow.DocumentEvent
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:   "document ID",
+			values: map[string]string{"document_id": "123"},
+			want:   workflow.DocumentEvent{DocumentID: 123, Trigger: workflow.TriggerAdded},
+		},
+		{
+			name: "document URL",
+			values: map[string]string{
+				"doc_url": "https://paperless.example.com/documents/45/",
+				"trigger": "Updated",
+			},
+			want: workflow.DocumentEvent{DocumentID: 45, Trigger: workflow.TriggerUpdated},
+		},
+		{
+			name: "ID takes precedence",
+			values: map[string]string{
+				"document_id": "7",
+				"doc_url":     "https://paperless.example.com/documents/45/",
+				"trigger":     "document_added",
+			},
+			want: workflow.DocumentEvent{DocumentID: 7, Trigger: workflow.TriggerAdded},
+		},
+		{
+			name:    "invalid ID",
+			values:  map[string]string{"document_id": "abc"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "zero ID",
+			values:  map[string]string{"document_id": "0"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "unknown URL",
+			values:  map[string]string{"doc_url": "https://paperless.example.com/tags/1/"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "unknown trigger",
+			values:  map[string]string{"document_id": "1", "trigger": "scheduled"},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseWebhookEvent(tc.values)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("Event diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleWebhook(t *testing.T) {
+	const secret = "s3cret"
+
+	for _, tc := range []struct {
+		name        string
+		secret      string
+		header      map[string]string
+		contentType string
+		body        string
+		wantStatus  int
+		wantEvents  []workflow.DocumentEvent
+	}{
+		{
+			name:       "not configured",
+			header:     map[string]string{"Authorization": "Bearer "},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing secret",
+			secret:     secret,
+			body:       "document_id=1",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:        "wrong token",
+			secret:      secret,
+			header:      map[string]string{"Authorization": "Bearer wrong"},
+			contentType: "application/x-www-form-urlencoded",
+			body:        "document_id=1",
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "token with form",
+			secret:      secret,
+			header:      map[string]string{"Authorization": "Bearer " + secret},
+			contentType: "application/x-www-form-urlencoded",
+			body:        "document_id=12&trigger=added",
+			wantStatus:  http.StatusNoContent,
+			wantEvents: []workflow.DocumentEvent{
+				{DocumentID: 12, Trigger: workflow.TriggerAdded},
+			},
+		},
+		{
+			name:        "signed json",
+			secret:      secret,
+			header:      map[string]string{webhookSignatureHeader: signWebhook(secret, `{"doc_url": "http://localhost/documents/34/", "trigger": "updated"}`)},
+			contentType: "application/json",
+			body:        `{"doc_url": "http://localhost/documents/34/", "trigger": "updated"}`,
+			wantStatus:  http.StatusNoContent,
+			wantEvents: []workflow.DocumentEvent{
+				{DocumentID: 34, Trigger: workflow.TriggerUpdated},
+			},
+		},
+		{
+			name:        "json number",
+			secret:      secret,
+			header:      map[string]string{"Authorization": "Bearer " + secret},
+			contentType: "application/json",
+			body:        `{"document_id": 56}`,
+			wantStatus:  http.StatusNoContent,
+			wantEvents: []workflow.DocumentEvent{
+				{DocumentID: 56, Trigger: workflow.TriggerAdded},
+			},
+		},
+		{
+			name:        "bad signature",
+			secret:      secret,
+			header:      map[string]string{webhookSignatureHeader: signWebhook("other", `{"document_id": "1"}`)},
+			contentType: "application/json",
+			body:        `{"document_id": "1"}`,
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "invalid payload",
+			secret:      secret,
+			header:      map[string]string{"Authorization": "Bearer " + secret},
+			contentType: "application/json",
+			body:        `{"title": "x"}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			wf := &fakeEventWorkflow{}
+
+			p := &Program{
+				logger:          zaptest.NewLogger(t),
+				metricsRegistry: prometheus.NewPedanticRegistry(),
+				webhookSecret:   tc.secret,
+				workflows:       []workflow.Workflow{wf},
+			}
+			p.setupMux()
+
+			r := httptest.NewRequest(http.MethodPost, "/webhooks/paperless", strings.NewReader(tc.body))
+
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+
+			for key, value := range tc.header {
+				r.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+
+			p.mux.ServeHTTP(rec, r)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+
+			if diff := cmp.Diff(tc.wantEvents, wf.events); diff != "" {
+				t.Errorf("Events diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAuthenticatedMux(t *testing.T) {
+	const secret = "s3cret"
+
+	for _, tc := range []struct {
+		name       string
+		secret     string
+		header     map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "not configured",
+			header:     map[string]string{"Authorization": "Bearer "},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing secret",
+			secret:     secret,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token",
+			secret:     secret,
+			header:     map[string]string{"Authorization": "Bearer " + secret},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "signature",
+			secret:     secret,
+			header:     map[string]string{webhookSignatureHeader: signWebhook(secret, "")},
+			wantStatus: http.StatusOK,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Program{
+				logger:          zaptest.NewLogger(t),
+				metricsRegistry: prometheus.NewPedanticRegistry(),
+				webhookSecret:   tc.secret,
+			}
+			p.setupMux()
+
+			env := &workflowEnvBase{p: p}
+			env.AuthenticatedMux().Post("/documents/{id}/process", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/documents/1/process", nil)
+
+			for key, value := range tc.header {
+				r.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+
+			p.mux.ServeHTTP(rec, r)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("Status %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
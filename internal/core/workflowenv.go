@@ -6,6 +6,7 @@ import (
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-chi/chi/v5"
 	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer/internal/facter"
 	"github.com/hansmi/paperminer/internal/objectresolver"
 	"github.com/hansmi/paperminer/internal/workflow"
 	"github.com/hansmi/staticplug"
@@ -37,6 +38,10 @@ func (e *workflowEnvBase) PluginRegistry() *staticplug.Registry {
 	return e.p.pluginRegistry
 }
 
+func (e *workflowEnvBase) FacterOptions() facter.Options {
+	return e.p.facterOptions
+}
+
 func (e *workflowEnvBase) MetricsRegistry() prometheus.Registerer {
 	return e.p.metricsRegistry
 }
@@ -45,6 +50,10 @@ func (e *workflowEnvBase) Mux() *chi.Mux {
 	return e.p.mux
 }
 
+func (e *workflowEnvBase) AuthenticatedMux() chi.Router {
+	return e.p.mux.With(e.p.requireWebhookSecret)
+}
+
 func (e *workflowEnvBase) Store() *bolthold.Store {
 	e.mu.Lock()
 	defer e.mu.Unlock()
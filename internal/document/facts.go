@@ -5,20 +5,50 @@ import (
 	"fmt"
 
 	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/facter"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
-type ExtractVariantFactsFunc func(context.Context, Variant) (*paperminer.Facts, error)
+type ExtractVariantFactsFunc func(context.Context, Variant) (facter.FactsSlice, error)
 
 type ExtractFactsOptions struct {
 	Logger   *zap.Logger
 	Variants []Variant
 	Extract  ExtractVariantFactsFunc
+
+	// Gather facts from all variants before selecting the best. By default
+	// the facts from the first variant producing any are used.
+	AllVariants bool
+}
+
+// addCandidates appends facts to a slice unless facts from the same reporter
+// are present already. Facts from earlier variants are preferred.
+func addCandidates(all facter.FactsSlice, facts facter.FactsSlice) facter.FactsSlice {
+	seen := map[string]struct{}{}
+
+	for _, f := range all {
+		if f.Reporter != nil {
+			seen[*f.Reporter] = struct{}{}
+		}
+	}
+
+	for _, f := range facts {
+		if f.Reporter != nil {
+			if _, ok := seen[*f.Reporter]; ok {
+				continue
+			}
+		}
+
+		all = append(all, f)
+	}
+
+	return all
 }
 
 func ExtractFacts(ctx context.Context, o ExtractFactsOptions) (*paperminer.Facts, error) {
 	var allErr error
+	var candidates facter.FactsSlice
 
 	for _, v := range o.Variants {
 		facts, err := o.Extract(ctx, v)
@@ -27,17 +57,35 @@ func ExtractFacts(ctx context.Context, o ExtractFactsOptions) (*paperminer.Facts
 			continue
 		}
 
-		if !(facts == nil || facts.IsEmpty()) {
-			if allErr != nil {
-				o.Logger.Debug("Fact extraction successful after earlier failure",
-					zap.Stringer("success_variant", v),
-					zap.NamedError("previous_errors", allErr),
-				)
+		var found facter.FactsSlice
+
+		for _, f := range facts {
+			if !(f == nil || f.IsEmpty()) {
+				found = append(found, f)
 			}
+		}
+
+		if len(found) == 0 {
+			continue
+		}
 
-			return facts, nil
+		if allErr != nil {
+			o.Logger.Debug("Fact extraction successful after earlier failure",
+				zap.Stringer("success_variant", v),
+				zap.NamedError("previous_errors", allErr),
+			)
 		}
+
+		candidates = addCandidates(candidates, found)
+
+		if !o.AllVariants {
+			break
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, allErr
 	}
 
-	return nil, allErr
+	return candidates.Best()
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/facter"
 	"github.com/hansmi/paperminer/internal/ref"
 	"go.uber.org/zap/zaptest"
 )
@@ -35,20 +36,63 @@ func TestExtractFacts(t *testing.T) {
 			name: "first variant fails",
 			opts: ExtractFactsOptions{
 				Variants: []Variant{Original, Archived},
-				Extract: func(_ context.Context, v Variant) (*paperminer.Facts, error) {
+				Extract: func(_ context.Context, v Variant) (facter.FactsSlice, error) {
 					if v == Original {
 						return nil, errTest
 					}
 
-					return &paperminer.Facts{
+					return facter.FactsSlice{{
 						Title: ref.Ref("test title"),
-					}, nil
+					}}, nil
 				},
 			},
 			want: &paperminer.Facts{
 				Title: ref.Ref("test title"),
 			},
 		},
+		{
+			name: "first variant only",
+			opts: ExtractFactsOptions{
+				Variants: []Variant{Archived, Original},
+				Extract: func(_ context.Context, v Variant) (facter.FactsSlice, error) {
+					return facter.FactsSlice{{
+						Reporter: ref.Ref(v.String()),
+						Title:    ref.Ref(v.String()),
+					}}, nil
+				},
+			},
+			want: &paperminer.Facts{
+				Reporter: ref.Ref("archived"),
+				Title:    ref.Ref("archived"),
+			},
+		},
+		{
+			name: "all variants",
+			opts: ExtractFactsOptions{
+				Variants:    []Variant{Archived, Original},
+				AllVariants: true,
+				Extract: func(_ context.Context, v Variant) (facter.FactsSlice, error) {
+					result := facter.FactsSlice{{
+						Reporter: ref.Ref("both"),
+						Title:    ref.Ref(v.String()),
+					}}
+
+					if v == Original {
+						result = append(result, &paperminer.Facts{
+							Reporter: ref.Ref("original only"),
+							SetTags:  []string{"tag"},
+						}, &paperminer.Facts{})
+					}
+
+					return result, nil
+				},
+			},
+			want: &paperminer.Facts{
+				Reporter: ref.Ref("both, original only"),
+				Title:    ref.Ref("archived"),
+				SetTags:  []string{"tag"},
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
@@ -57,7 +101,7 @@ func TestExtractFacts(t *testing.T) {
 			tc.opts.Logger = zaptest.NewLogger(t)
 
 			if tc.opts.Extract == nil {
-				tc.opts.Extract = func(context.Context, Variant) (*paperminer.Facts, error) {
+				tc.opts.Extract = func(context.Context, Variant) (facter.FactsSlice, error) {
 					return nil, nil
 				}
 			}
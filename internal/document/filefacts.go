@@ -4,22 +4,47 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/hansmi/dossier"
+	"github.com/hansmi/paperminer"
 	"github.com/hansmi/paperminer/internal/facter"
 	"go.uber.org/zap"
 )
 
-type ExtractDocFactsFunc func(context.Context, *zap.Logger, *dossier.Document) (facter.FactsSlice, error)
+type ExtractDocFactsFunc func(context.Context, paperminer.DocumentFacterOptions) (facter.FactsSlice, error)
 
-func MakeFileFactsExtractor(extract ExtractDocFactsFunc, opts ...dossier.DocumentOption) ExtractFileFactsFunc {
-	return func(ctx context.Context, logger *zap.Logger, path string) (facter.FactsSlice, error) {
-		doc := dossier.NewDocument(path, opts...)
+// File is a local document file.
+type File struct {
+	Path string
 
-		if err := doc.Validate(ctx); err != nil {
-			return nil, fmt.Errorf("file validation: %w", err)
+	// Content type as reported by the source of the file. May be empty.
+	ContentType string
+
+	// Name used to determine the format when the content type is empty or
+	// unknown. May be empty.
+	Filename string
+}
+
+// MakeFileFactsExtractor returns a function parsing a document file according
+// to its format before extracting facts. Unsupported formats are reported as
+// permanent errors.
+func MakeFileFactsExtractor(extract ExtractDocFactsFunc, formats *Formats) ExtractFileFactsFunc {
+	return func(ctx context.Context, logger *zap.Logger, info *paperminer.DocumentInfo, file File) (facter.FactsSlice, error) {
+		format, err := formats.Lookup(file.ContentType, file.Filename)
+		if err != nil {
+			return nil, paperminer.Permanent(err)
+		}
+
+		opts := paperminer.DocumentFacterOptions{
+			Logger: logger,
+			Info:   info,
+			Path:   file.Path,
+			Format: format.MIMEType,
+		}
+
+		if err := format.Parse(ctx, file.Path, &opts); err != nil {
+			return nil, err
 		}
 
-		all, err := extract(ctx, logger, doc)
+		all, err := extract(ctx, opts)
 		if err != nil {
 			return nil, fmt.Errorf("fact extraction: %w", err)
 		}
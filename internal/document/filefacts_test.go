@@ -10,47 +10,77 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hansmi/dossier"
 	"github.com/hansmi/dossier/pkg/parsertest"
+	"github.com/hansmi/paperminer"
 	"github.com/hansmi/paperminer/internal/facter"
+	"github.com/hansmi/paperminer/internal/ref"
 	"github.com/hansmi/paperminer/internal/testutil"
-	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
 
 func TestMakeFileFactsExtractor(t *testing.T) {
-	emptyFile := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "empty"), "")
+	tmpdir := t.TempDir()
+	emptyFile := testutil.MustWriteFileString(t, filepath.Join(tmpdir, "empty.pdf"), "")
+	textFile := testutil.MustWriteFileString(t, filepath.Join(tmpdir, "upload"), "Hello World")
 
 	for _, tc := range []struct {
 		name    string
-		path    string
+		file    File
 		opts    []dossier.DocumentOption
-		extract ExtractDocFactsFunc
 		wantErr error
 		want    facter.FactsSlice
 	}{
 		{
 			name:    "missing file",
-			path:    filepath.Join(t.TempDir(), "missing"),
+			file:    File{Path: filepath.Join(t.TempDir(), "missing"), Filename: "missing.pdf"},
 			wantErr: os.ErrNotExist,
 		},
 		{
 			name: "empty document",
-			path: emptyFile,
+			file: File{Path: emptyFile, ContentType: "application/pdf"},
 			opts: []dossier.DocumentOption{
 				dossier.WithStaticDocumentParser(&parsertest.SimpleParser{}),
 			},
+			want: facter.FactsSlice{{Title: ref.Ref("application/pdf")}},
+		},
+		{
+			name:    "unsupported format",
+			file:    File{Path: emptyFile, ContentType: "image/png", Filename: "scan.png"},
+			wantErr: paperminer.ErrPermanent,
+		},
+		{
+			name: "text",
+			file: File{Path: textFile, ContentType: "text/plain; charset=utf-8"},
+			want: facter.FactsSlice{{Title: ref.Ref("text/plain Hello World")}},
+		},
+		{
+			name: "text by extension",
+			file: File{Path: textFile, ContentType: "application/octet-stream", Filename: "note.TXT"},
+			want: facter.FactsSlice{{Title: ref.Ref("text/plain Hello World")}},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			t.Cleanup(cancel)
 
-			if tc.extract == nil {
-				tc.extract = func(context.Context, *zap.Logger, *dossier.Document) (facter.FactsSlice, error) {
-					return nil, nil
+			extract := func(_ context.Context, opts paperminer.DocumentFacterOptions) (facter.FactsSlice, error) {
+				if opts.Path != tc.file.Path {
+					t.Errorf("Got path %q, want %q", opts.Path, tc.file.Path)
 				}
+
+				if (opts.Document != nil) != (opts.Format == paperminer.FormatPDF) {
+					t.Errorf("Document is %v for format %q", opts.Document, opts.Format)
+				}
+
+				title := opts.Format
+
+				if opts.Text != "" {
+					title += " " + opts.Text
+				}
+
+				return facter.FactsSlice{{Title: &title}}, nil
 			}
 
-			got, err := MakeFileFactsExtractor(tc.extract, tc.opts...)(ctx, zaptest.NewLogger(t), tc.path)
+			got, err := MakeFileFactsExtractor(extract, DefaultFormats(tc.opts...))(ctx, zaptest.NewLogger(t), nil, tc.file)
 
 			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
 				t.Errorf("Error diff (-want +got):\n%s", diff)
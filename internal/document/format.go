@@ -0,0 +1,174 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hansmi/dossier"
+	"github.com/hansmi/paperminer"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported document format")
+
+// ParseFunc parses a document file and sets the format-specific members of
+// the facter options.
+type ParseFunc func(ctx context.Context, path string, opts *paperminer.DocumentFacterOptions) error
+
+// Format describes how to parse document files of a particular type.
+type Format struct {
+	// MIME type, e.g. "application/pdf". Given to facters.
+	MIMEType string
+
+	// Filename extensions including the leading dot, e.g. ".pdf". Used when
+	// the content type of a file is unknown. Case-insensitive.
+	Extensions []string
+
+	Parse ParseFunc
+}
+
+// Formats chooses the format of document files by their content type or
+// filename extension.
+type Formats struct {
+	byType map[string]*Format
+	byExt  map[string]*Format
+}
+
+func NewFormats() *Formats {
+	return &Formats{
+		byType: map[string]*Format{},
+		byExt:  map[string]*Format{},
+	}
+}
+
+// Register adds a format. MIME types and extensions must be unique.
+func (f *Formats) Register(format Format) error {
+	if format.MIMEType == "" || format.Parse == nil {
+		return fmt.Errorf("%w: format requires a MIME type and a parse function", os.ErrInvalid)
+	}
+
+	mediaType := strings.ToLower(format.MIMEType)
+
+	if _, ok := f.byType[mediaType]; ok {
+		return fmt.Errorf("%w: duplicate format %q", os.ErrInvalid, format.MIMEType)
+	}
+
+	for _, ext := range format.Extensions {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("%w: extension %q must start with a dot", os.ErrInvalid, ext)
+		}
+
+		if other, ok := f.byExt[strings.ToLower(ext)]; ok {
+			return fmt.Errorf("%w: extension %q used by %q already", os.ErrInvalid, ext, other.MIMEType)
+		}
+	}
+
+	format.Extensions = slices.Clone(format.Extensions)
+
+	f.byType[mediaType] = &format
+
+	for _, ext := range format.Extensions {
+		f.byExt[strings.ToLower(ext)] = &format
+	}
+
+	return nil
+}
+
+func (f *Formats) MustRegister(format Format) {
+	if err := f.Register(format); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the format for a content type. The filename extension is
+// used if the content type is empty, generic or unknown. Parameters of the
+// content type, e.g. the charset, are ignored.
+func (f *Formats) Lookup(contentType, filename string) (*Format, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if format, ok := f.byType[mediaType]; ok {
+			return format, nil
+		}
+	}
+
+	if ext := filepath.Ext(filename); ext != "" {
+		if format, ok := f.byExt[strings.ToLower(ext)]; ok {
+			return format, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: content type %q, filename %q", ErrUnsupportedFormat, contentType, filename)
+}
+
+// DefaultFormats returns the built-in formats: PDF (parsed by dossier), plain
+// text and e-mail.
+func DefaultFormats(opts ...dossier.DocumentOption) *Formats {
+	f := NewFormats()
+	f.MustRegister(Format{
+		MIMEType:   paperminer.FormatPDF,
+		Extensions: []string{".pdf"},
+		Parse: func(ctx context.Context, path string, fo *paperminer.DocumentFacterOptions) error {
+			doc := dossier.NewDocument(path, opts...)
+
+			if err := doc.Validate(ctx); err != nil {
+				return fmt.Errorf("file validation: %w", err)
+			}
+
+			fo.Document = doc
+
+			return nil
+		},
+	})
+	f.MustRegister(Format{
+		MIMEType:   paperminer.FormatText,
+		Extensions: []string{".txt", ".text"},
+		Parse:      parseText,
+	})
+	f.MustRegister(Format{
+		MIMEType:   paperminer.FormatMail,
+		Extensions: []string{".eml"},
+		Parse:      parseMail,
+	})
+
+	return f
+}
+
+func parseText(ctx context.Context, path string, opts *paperminer.DocumentFacterOptions) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	opts.Text = decodeCharset("", data)
+
+	return nil
+}
+
+// decodeCharset converts text to UTF-8. Only UTF-8 and ISO 8859-1 are
+// supported; other charsets are treated as UTF-8 with invalid sequences
+// replaced.
+func decodeCharset(charset string, data []byte) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "l1":
+		var sb strings.Builder
+
+		sb.Grow(len(data))
+
+		for _, b := range data {
+			sb.WriteRune(rune(b))
+		}
+
+		return sb.String()
+	}
+
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	return strings.ToValidUTF8(string(data), "�")
+}
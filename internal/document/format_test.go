@@ -0,0 +1,214 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/testutil"
+)
+
+func TestFormatsRegister(t *testing.T) {
+	parse := func(context.Context, string, *paperminer.DocumentFacterOptions) error {
+		return nil
+	}
+
+	for _, tc := range []struct {
+		name    string
+		format  Format
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			format: Format{MIMEType: "image/png", Extensions: []string{".png"}, Parse: parse},
+		},
+		{
+			name:    "missing MIME type",
+			format:  Format{Parse: parse},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "missing parse function",
+			format:  Format{MIMEType: "image/png"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "duplicate MIME type",
+			format:  Format{MIMEType: "Text/Plain", Parse: parse},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "duplicate extension",
+			format:  Format{MIMEType: "text/x-mail", Extensions: []string{".EML"}, Parse: parse},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "extension without dot",
+			format:  Format{MIMEType: "image/png", Extensions: []string{"png"}, Parse: parse},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DefaultFormats().Register(tc.format)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatsLookup(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		contentType string
+		filename    string
+		want        string
+		wantErr     error
+	}{
+		{name: "PDF", contentType: "application/pdf", want: paperminer.FormatPDF},
+		{name: "parameters", contentType: "text/plain; charset=iso-8859-1", filename: "x.pdf", want: paperminer.FormatText},
+		{name: "extension", filename: "Mail.EML", want: paperminer.FormatMail},
+		{name: "generic type", contentType: "application/octet-stream", filename: "doc.pdf", want: paperminer.FormatPDF},
+		{name: "invalid type", contentType: "/", filename: "notes.txt", want: paperminer.FormatText},
+		{name: "unsupported", contentType: "image/png", filename: "scan.png", wantErr: ErrUnsupportedFormat},
+		{name: "empty", wantErr: ErrUnsupportedFormat},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DefaultFormats().Lookup(tc.contentType, tc.filename)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err == nil && got.MIMEType != tc.want {
+				t.Errorf("Lookup() returned %q, want %q", got.MIMEType, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "text"), "valid\xffinvalid")
+
+	var opts paperminer.DocumentFacterOptions
+
+	if err := parseText(context.Background(), path, &opts); err != nil {
+		t.Errorf("parseText() failed: %v", err)
+	}
+
+	if diff := cmp.Diff("valid�invalid", opts.Text); diff != "" {
+		t.Errorf("Text diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseMail(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		input       string
+		wantErr     bool
+		wantSubject string
+		wantText    string
+	}{
+		{
+			name:    "invalid",
+			input:   "no header",
+			wantErr: true,
+		},
+		{
+			name: "plain",
+			input: `From: ACME <billing@example.com>
+Subject: Invoice 123
+
+Please find attached.
+`,
+			wantSubject: "Invoice 123",
+			wantText:    "Please find attached.\n",
+		},
+		{
+			name: "quoted-printable latin1",
+			input: `Subject: =?iso-8859-1?q?Rechnung_M=E4rz?=
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Gr=FC=DFe
+`,
+			wantSubject: "=?iso-8859-1?q?Rechnung_M=E4rz?=",
+			wantText:    "Grüße\n",
+		},
+		{
+			name: "multipart",
+			input: `Subject: Statement
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/html
+
+<p>HTML</p>
+--inner
+Content-Type: text/plain; charset=utf-8
+Content-Transfer-Encoding: base64
+
+WW91ciBzdGF0ZW1l
+bnQ=
+--inner--
+--outer
+Content-Type: text/plain
+Content-Disposition: attachment; filename="other.txt"
+
+Attachment
+--outer--
+`,
+			wantSubject: "Statement",
+			wantText:    "Your statement",
+		},
+		{
+			name: "attachment only",
+			input: `Subject: Scan
+Content-Type: multipart/mixed; boundary="b"
+
+--b
+Content-Type: text/plain
+Content-Disposition: attachment
+
+Attachment
+--b--
+`,
+			wantSubject: "Scan",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			input := strings.ReplaceAll(tc.input, "\n", "\r\n")
+			path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "mail.eml"), input)
+
+			var opts paperminer.DocumentFacterOptions
+
+			err := parseMail(context.Background(), path, &opts)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("parseMail() returned error %v, want error %t", err, tc.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := opts.MailHeader.Get("Subject"); got != tc.wantSubject {
+				t.Errorf("Got subject %q, want %q", got, tc.wantSubject)
+			}
+
+			if diff := cmp.Diff(tc.wantText, strings.ReplaceAll(opts.Text, "\r\n", "\n")); diff != "" {
+				t.Errorf("Text diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
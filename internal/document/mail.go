@@ -0,0 +1,104 @@
+package document
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+
+	"github.com/hansmi/paperminer"
+)
+
+// Maximum nesting of multipart bodies.
+const maxMailDepth = 10
+
+func parseMail(ctx context.Context, path string, opts *paperminer.DocumentFacterOptions) error {
+	fh, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	msg, err := mail.ReadMessage(bufio.NewReader(fh))
+	if err != nil {
+		return fmt.Errorf("parsing e-mail: %w", err)
+	}
+
+	text, _, err := mailText(textproto.MIMEHeader(msg.Header), msg.Body, 0)
+	if err != nil {
+		return fmt.Errorf("parsing e-mail body: %w", err)
+	}
+
+	opts.MailHeader = msg.Header
+	opts.Text = text
+
+	return nil
+}
+
+func transferDecoder(header textproto.MIMEHeader, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	}
+
+	return r
+}
+
+// mailText returns the first plain text part of a message body which isn't
+// an attachment. The returned boolean reports whether a part was found.
+func mailText(header textproto.MIMEHeader, body io.Reader, depth int) (string, bool, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// Default as per RFC 2045, section 5.2.
+		mediaType = "text/plain"
+		params = nil
+	}
+
+	if disposition, _, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && disposition == "attachment" {
+		return "", false, nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if depth >= maxMailDepth {
+			return "", false, errors.New("multipart nesting too deep")
+		}
+
+		mr := multipart.NewReader(body, params["boundary"])
+
+		for {
+			part, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return "", false, nil
+			} else if err != nil {
+				return "", false, err
+			}
+
+			if text, ok, err := mailText(part.Header, part, depth+1); err != nil || ok {
+				return text, ok, err
+			}
+		}
+
+	case mediaType == "text/plain":
+		data, err := io.ReadAll(transferDecoder(header, body))
+		if err != nil {
+			return "", false, err
+		}
+
+		return decodeCharset(params["charset"], data), true, nil
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,24 @@
+package document
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/pdftext"
+)
+
+// Text returns the text content of a parsed document file. The text of PDF
+// documents is extracted using the pdftotext program from Poppler.
+func Text(ctx context.Context, opts paperminer.DocumentFacterOptions) (string, error) {
+	if opts.Format != paperminer.FormatPDF {
+		return opts.Text, nil
+	}
+
+	pages, err := pdftext.Pages(ctx, opts.Path, 0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(pages, "\n"), nil
+}
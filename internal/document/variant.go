@@ -12,4 +12,7 @@ var _ fmt.Stringer = (*Variant)(nil)
 const (
 	Archived Variant = iota // archived
 	Original                // original
+
+	// Text content as provided by Paperless. Doesn't require a download.
+	Content // content
 )
@@ -10,11 +10,12 @@ func _() {
 	var x [1]struct{}
 	_ = x[Archived-0]
 	_ = x[Original-1]
+	_ = x[Content-2]
 }
 
-const _Variant_name = "archivedoriginal"
+const _Variant_name = "archivedoriginalcontent"
 
-var _Variant_index = [...]uint8{0, 8, 16}
+var _Variant_index = [...]uint8{0, 8, 16, 23}
 
 func (i Variant) String() string {
 	if i < 0 || i >= Variant(len(_Variant_index)-1) {
@@ -21,7 +21,7 @@ type VariantFactsClient interface {
 
 type docDownloadFunc func(context.Context, io.Writer, int64) (*plclient.DownloadResult, *plclient.Response, error)
 
-type ExtractFileFactsFunc func(context.Context, *zap.Logger, string) (facter.FactsSlice, error)
+type ExtractFileFactsFunc func(context.Context, *zap.Logger, *paperminer.DocumentInfo, File) (facter.FactsSlice, error)
 
 type ExtractVariantFactsOptions struct {
 	Logger *zap.Logger
@@ -37,6 +37,9 @@ type ExtractVariantFactsOptions struct {
 
 	ID      int64
 	Variant Variant
+
+	// Description of the document given to facters. May be nil.
+	Info *paperminer.DocumentInfo
 }
 
 func selectDownloadFunction(cl VariantFactsClient, v Variant) (docDownloadFunc, error) {
@@ -54,31 +57,35 @@ func selectDownloadFunction(cl VariantFactsClient, v Variant) (docDownloadFunc,
 
 // Download a document into a temporary file. The caller is responsible for
 // removing the directory when the document is no longer used.
-func download(ctx context.Context, logger *zap.Logger, tmpdir string, fn docDownloadFunc, id int64) (string, error) {
+func download(ctx context.Context, logger *zap.Logger, tmpdir string, fn docDownloadFunc, id int64) (_ File, err error) {
 	file, err := os.CreateTemp(tmpdir, "")
 	if err != nil {
-		return "", err
+		return File{}, err
 	}
 
 	defer multierr.AppendFunc(&err, file.Close)
 
 	dl, _, err := fn(ctx, file, id)
 	if err != nil {
-		return "", err
+		return File{}, err
 	}
 
 	logger.Info("Received document",
 		zap.Int64("length_bytes", dl.Length),
+		zap.String("content_type", dl.ContentType),
 		zap.String("suggested_filename", dl.Filename),
 	)
 
-	return file.Name(), nil
+	return File{
+		Path:        file.Name(),
+		ContentType: dl.ContentType,
+		Filename:    dl.Filename,
+	}, nil
 }
 
 // ExtractVariantFacts downloads a particular document variant to a temporary
-// directory before using an extraction function to get all facts. Among those,
-// if any, the best are chosen and returned.
-func ExtractVariantFacts(ctx context.Context, o ExtractVariantFactsOptions) (_ *paperminer.Facts, err error) {
+// directory before using an extraction function to get all facts.
+func ExtractVariantFacts(ctx context.Context, o ExtractVariantFactsOptions) (_ facter.FactsSlice, err error) {
 	fn, err := selectDownloadFunction(o.Client, o.Variant)
 	if err != nil {
 		return nil, err
@@ -91,15 +98,19 @@ func ExtractVariantFacts(ctx context.Context, o ExtractVariantFactsOptions) (_ *
 
 	defer multierr.AppendFunc(&err, cleanup)
 
-	path, err := download(ctx, o.Logger, tmpdir, fn, o.ID)
+	file, err := download(ctx, o.Logger, tmpdir, fn, o.ID)
 	if err != nil {
 		return nil, fmt.Errorf("download: %w", err)
 	}
 
-	all, err := o.Extract(ctx, o.Logger, path)
+	if file.Filename == "" && o.Variant == Original && o.Info != nil {
+		file.Filename = o.Info.OriginalFileName
+	}
+
+	all, err := o.Extract(ctx, o.Logger, o.Info, file)
 	if err != nil {
-		return nil, fmt.Errorf("extracting facts from %q: %w", path, err)
+		return nil, fmt.Errorf("extracting facts from %q: %w", file.Path, err)
 	}
 
-	return all.Best()
+	return all, nil
 }
@@ -16,16 +16,17 @@ import (
 )
 
 type fakeVariantFactsClient struct {
+	result      plclient.DownloadResult
 	originalErr error
 	archivedErr error
 }
 
 func (c *fakeVariantFactsClient) DownloadDocumentOriginal(context.Context, io.Writer, int64) (*plclient.DownloadResult, *plclient.Response, error) {
-	return &plclient.DownloadResult{}, nil, c.originalErr
+	return &c.result, nil, c.originalErr
 }
 
 func (c *fakeVariantFactsClient) DownloadDocumentArchived(context.Context, io.Writer, int64) (*plclient.DownloadResult, *plclient.Response, error) {
-	return &plclient.DownloadResult{}, nil, c.archivedErr
+	return &c.result, nil, c.archivedErr
 }
 
 func TestExtractVariantFacts(t *testing.T) {
@@ -36,7 +37,8 @@ func TestExtractVariantFacts(t *testing.T) {
 		cl      VariantFactsClient
 		extract ExtractFileFactsFunc
 		variant Variant
-		want    *paperminer.Facts
+		info    *paperminer.DocumentInfo
+		want    facter.FactsSlice
 		wantErr error
 	}{
 		{name: "defaults"},
@@ -51,19 +53,35 @@ func TestExtractVariantFacts(t *testing.T) {
 		},
 		{
 			name: "facts",
-			extract: func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, File) (facter.FactsSlice, error) {
 				return facter.FactsSlice{{
 					Title: plclient.String("Test"),
 				}}, nil
 			},
 			variant: Archived,
-			want: &paperminer.Facts{
+			want: facter.FactsSlice{{
 				Title: plclient.String("Test"),
+			}},
+		},
+		{
+			name: "file",
+			cl: &fakeVariantFactsClient{
+				result: plclient.DownloadResult{ContentType: "message/rfc822"},
 			},
+			extract: func(_ context.Context, _ *zap.Logger, _ *paperminer.DocumentInfo, file File) (facter.FactsSlice, error) {
+				return facter.FactsSlice{{
+					Title: plclient.String(file.ContentType + " " + file.Filename),
+				}}, nil
+			},
+			variant: Original,
+			info:    &paperminer.DocumentInfo{OriginalFileName: "mail.eml"},
+			want: facter.FactsSlice{{
+				Title: plclient.String("message/rfc822 mail.eml"),
+			}},
 		},
 		{
 			name: "no facts",
-			extract: func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+			extract: func(context.Context, *zap.Logger, *paperminer.DocumentInfo, File) (facter.FactsSlice, error) {
 				return nil, nil
 			},
 			variant: Archived,
@@ -78,7 +96,7 @@ func TestExtractVariantFacts(t *testing.T) {
 			}
 
 			if tc.extract == nil {
-				tc.extract = func(context.Context, *zap.Logger, string) (facter.FactsSlice, error) {
+				tc.extract = func(context.Context, *zap.Logger, *paperminer.DocumentInfo, File) (facter.FactsSlice, error) {
 					return nil, nil
 				}
 			}
@@ -89,6 +107,7 @@ func TestExtractVariantFacts(t *testing.T) {
 				Client:  tc.cl,
 				Extract: tc.extract,
 				Variant: tc.variant,
+				Info:    tc.info,
 			}
 
 			got, err := ExtractVariantFacts(ctx, opts)
@@ -0,0 +1,94 @@
+// Package factbuilder builds facts from a title template, a date and fixed
+// values as configured for declarative facters.
+package factbuilder
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/localdate"
+	"go.uber.org/multierr"
+)
+
+// ValidateDate checks the layout and locale for parsing a date.
+func ValidateDate(layout, locale string) error {
+	if layout == "" {
+		return fmt.Errorf("%w: created date layout is required", os.ErrInvalid)
+	}
+
+	if !localdate.ValidLocale(locale) {
+		return fmt.Errorf("%w: unsupported locale %q", os.ErrInvalid, locale)
+	}
+
+	return nil
+}
+
+// Builder describes the facts to build.
+type Builder struct {
+	// Template producing the title. Whitespace is trimmed from the result
+	// and an empty title is not set.
+	Title *template.Template
+
+	// Layout and locale of the created date (see [localdate.Parse]). Required
+	// for the date to be set.
+	CreatedLayout string
+	CreatedLocale string
+
+	Correspondent string
+	DocumentType  string
+	StoragePath   string
+	SetTags       []string
+}
+
+// Build returns facts with the fixed values set. The title template is
+// executed with the given functions. The created date is parsed from the
+// given text unless it's empty.
+func (b *Builder) Build(funcs template.FuncMap, created string) (*paperminer.Facts, error) {
+	var err error
+
+	facts := &paperminer.Facts{
+		SetTags: slices.Clone(b.SetTags),
+	}
+
+	if b.Title != nil {
+		var sb strings.Builder
+
+		if execErr := template.Must(b.Title.Clone()).Funcs(funcs).Execute(&sb, nil); execErr != nil {
+			multierr.AppendInto(&err, fmt.Errorf("title: %w", execErr))
+		} else if title := strings.TrimSpace(sb.String()); title != "" {
+			facts.Title = &title
+		}
+	}
+
+	if b.CreatedLayout != "" && created != "" {
+		if ts, parseErr := localdate.Parse(b.CreatedLayout, b.CreatedLocale, created); parseErr != nil {
+			multierr.AppendInto(&err, fmt.Errorf("created: %w", parseErr))
+		} else {
+			facts.Created = &ts
+		}
+	}
+
+	for _, i := range []struct {
+		dst   **string
+		value string
+	}{
+		{&facts.Correspondent, b.Correspondent},
+		{&facts.DocumentType, b.DocumentType},
+		{&facts.StoragePath, b.StoragePath},
+	} {
+		if i.value != "" {
+			value := i.value
+			*i.dst = &value
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return facts, nil
+}
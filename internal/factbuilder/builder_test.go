@@ -0,0 +1,99 @@
+package factbuilder
+
+import (
+	"os"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+)
+
+func TestValidateDate(t *testing.T) {
+	for _, tc := range []struct {
+		layout  string
+		locale  string
+		wantErr error
+	}{
+		{layout: "2006-01-02"},
+		{layout: "2. January 2006", locale: "de"},
+		{wantErr: os.ErrInvalid},
+		{layout: "2006", locale: "xx-unknown", wantErr: os.ErrInvalid},
+	} {
+		err := ValidateDate(tc.layout, tc.locale)
+
+		if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("ValidateDate(%q, %q) error diff (-want +got):\n%s", tc.layout, tc.locale, diff)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	funcs := template.FuncMap{
+		"value": func() string { return " 123 " },
+	}
+
+	for _, tc := range []struct {
+		name    string
+		b       Builder
+		created string
+		want    *paperminer.Facts
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: &paperminer.Facts{},
+		},
+		{
+			name: "all",
+			b: Builder{
+				Title:         template.Must(template.New("").Funcs(funcs).Parse(`Invoice {{ value }}`)),
+				CreatedLayout: "2006-01-02",
+				Correspondent: "ACME",
+				DocumentType:  "Invoice",
+				StoragePath:   "invoices",
+				SetTags:       []string{"tag"},
+			},
+			created: "2024-03-05",
+			want: &paperminer.Facts{
+				Title:         ref.Ref("Invoice  123"),
+				Created:       ref.Ref(time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)),
+				Correspondent: ref.Ref("ACME"),
+				DocumentType:  ref.Ref("Invoice"),
+				StoragePath:   ref.Ref("invoices"),
+				SetTags:       []string{"tag"},
+			},
+		},
+		{
+			name: "empty title and date",
+			b: Builder{
+				Title:         template.Must(template.New("").Parse(`  `)),
+				CreatedLayout: "2006-01-02",
+			},
+			want: &paperminer.Facts{},
+		},
+		{
+			name: "invalid date",
+			b: Builder{
+				CreatedLayout: "2006-01-02",
+			},
+			created: "5 March",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.b.Build(funcs, tc.created)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Build() error %v, want error %v", err, tc.wantErr)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Build() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,11 +3,18 @@ package facter
 import (
 	"context"
 	"fmt"
+	"maps"
+	"os"
+	"reflect"
 	"runtime"
+	"slices"
 
-	"github.com/hansmi/dossier"
 	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/barcode"
+	"github.com/hansmi/paperminer/internal/barcodefacter"
 	"github.com/hansmi/paperminer/internal/ref"
+	"github.com/hansmi/paperminer/internal/rulefacter"
+	"github.com/hansmi/paperminer/internal/scriptfacter"
 	"github.com/hansmi/staticplug"
 	"github.com/sourcegraph/conc/stream"
 	"go.uber.org/multierr"
@@ -15,34 +22,184 @@ import (
 )
 
 var documentFacterType = staticplug.MustTypeOfInterface((*paperminer.DocumentFacter)(nil))
+var contentFacterType = staticplug.MustTypeOfInterface((*paperminer.ContentFacter)(nil))
 
-func GroupFromRegistry(reg *staticplug.Registry) (*Group, error) {
-	plugins, err := reg.PluginsImplementing(documentFacterType)
-	if err != nil {
+func GroupFromRegistry(reg *staticplug.Registry, opts Options) (*Group, error) {
+	facters := map[string]struct{}{}
+
+	for _, iface := range []reflect.Type{documentFacterType, contentFacterType} {
+		plugins, err := reg.PluginsImplementing(iface)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range plugins {
+			facters[p.Name] = struct{}{}
+		}
+	}
+
+	var candidates []staticplug.PluginInfo
+
+	// Retain the registry order.
+	for _, p := range reg.Plugins() {
+		if _, ok := facters[p.Name]; ok {
+			candidates = append(candidates, p)
+		}
+	}
+
+	if opts.RulesFile != "" {
+		if _, ok := facters[rulefacter.Name]; ok {
+			return nil, fmt.Errorf("%w: plugin name %q is reserved for the rule facter", os.ErrInvalid, rulefacter.Name)
+		}
+
+		rules, err := rulefacter.Load(opts.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		facters[rulefacter.Name] = struct{}{}
+		candidates = append(candidates, rules.PluginInfo())
+	}
+
+	for _, path := range opts.ScriptFiles {
+		name := scriptfacter.Name(path)
+
+		if _, ok := facters[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate facter name %q for script %s", os.ErrInvalid, name, path)
+		}
+
+		script, err := scriptfacter.Load(path, scriptfacter.Options{
+			Timeout:  opts.ScriptTimeout,
+			MaxSteps: opts.ScriptMaxSteps,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		facters[name] = struct{}{}
+		candidates = append(candidates, script.PluginInfo())
+	}
+
+	if opts.Barcodes {
+		if _, ok := facters[barcodefacter.Name]; ok {
+			return nil, fmt.Errorf("%w: plugin name %q is reserved for the barcode facter", os.ErrInvalid, barcodefacter.Name)
+		}
+
+		f := barcodefacter.New(barcodefacter.Options{
+			AmountField:    opts.BarcodeAmountField,
+			ReferenceField: opts.BarcodeReferenceField,
+		})
+
+		facters[barcodefacter.Name] = struct{}{}
+		candidates = append(candidates, f.PluginInfo())
+	}
+
+	if err := opts.validate(slices.Collect(maps.Keys(facters))); err != nil {
 		return nil, err
 	}
 
 	g := &Group{}
 
-	for _, p := range plugins {
+	if opts.Barcodes {
+		if opts.barcodeRender == nil {
+			if err := barcode.CheckPoppler(); err != nil {
+				return nil, err
+			}
+		}
+
+		g.scanner = barcode.NewScanner(barcode.Options{
+			RenderOptions: barcode.RenderOptions{
+				DPI:      opts.BarcodeDPI,
+				MaxPages: opts.BarcodeMaxPages,
+			},
+			Render: opts.barcodeRender,
+		})
+	}
+
+	for _, p := range candidates {
+
+		info := Info{
+			Name:     p.Name,
+			Enabled:  opts.enabled(p.Name),
+			Priority: opts.priority(p.Name),
+			Suggest:  opts.suggest(p.Name),
+		}
+
+		g.infos = append(g.infos, info)
+
+		if !info.Enabled {
+			continue
+		}
+
 		if inst, err := p.New(); err != nil {
 			return nil, fmt.Errorf("instantiating plugin %q: %w", p.Name, err)
 		} else {
-			g.plugins = append(g.plugins, newPluginWrapper(inst.(paperminer.DocumentFacter)))
+			w := newPluginWrapper(inst)
+			w.priority = info.Priority
+			w.suggest = info.Suggest
+
+			g.plugins = append(g.plugins, w)
 		}
 	}
 
 	return g, nil
 }
 
+// Info describes a registered facter.
+type Info struct {
+	Name    string
+	Enabled bool
+
+	// Priority overriding the priority of reported facts. Nil if not
+	// configured.
+	Priority *int
+
+	// Whether reported facts are proposed for review instead of applied.
+	Suggest bool
+}
+
 type Group struct {
 	plugins []*pluginWrapper
+
+	// All registered facters, including disabled ones.
+	infos []Info
+
+	// Scanner for barcodes on document pages. Nil if disabled.
+	scanner *barcode.Scanner
+}
+
+// Infos describes all registered facters, including disabled ones.
+func (g *Group) Infos() []Info {
+	return slices.Clone(g.infos)
 }
 
 func (g *Group) IsEmpty() bool {
 	return len(g.plugins) == 0
 }
 
+// HasDocumentFacters returns whether any plugin requires the parsed document
+// file.
+func (g *Group) HasDocumentFacters() bool {
+	for _, w := range g.plugins {
+		if w.document != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasContentFacters returns whether any plugin uses the document content.
+func (g *Group) HasContentFacters() bool {
+	for _, w := range g.plugins {
+		if w.content != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (g *Group) Names() []string {
 	result := make([]string, 0, len(g.plugins))
 
@@ -53,7 +210,11 @@ func (g *Group) Names() []string {
 	return result
 }
 
-func (g *Group) Extract(ctx context.Context, logger *zap.Logger, doc *dossier.Document) (FactsSlice, error) {
+type extractFunc func(context.Context, *zap.Logger, *pluginWrapper) (*paperminer.Facts, error)
+
+// extract invokes a function for all plugins concurrently. Plugins for which
+// the function returns neither facts nor an error are skipped.
+func (g *Group) extract(ctx context.Context, logger *zap.Logger, fn extractFunc) (FactsSlice, error) {
 	var result FactsSlice
 	var resultErr error
 
@@ -62,10 +223,7 @@ func (g *Group) Extract(ctx context.Context, logger *zap.Logger, doc *dossier.Do
 	for _, w := range g.plugins {
 		w := w
 		s.Go(func() stream.Callback {
-			facts, err := w.inst.DocumentFacts(ctx, paperminer.DocumentFacterOptions{
-				Logger:   logger.With(zap.String("plugin", w.name)),
-				Document: doc,
-			})
+			facts, err := fn(ctx, logger.With(zap.String("plugin", w.name)), w)
 
 			return func() {
 				if err != nil {
@@ -75,6 +233,14 @@ func (g *Group) Extract(ctx context.Context, logger *zap.Logger, doc *dossier.Do
 						facts.Reporter = ref.Ref(w.name)
 					}
 
+					if w.priority != nil {
+						facts.Priority = *w.priority
+					}
+
+					if w.suggest {
+						facts.Suggest = true
+					}
+
 					result = append(result, facts)
 				}
 			}
@@ -90,3 +256,46 @@ func (g *Group) Extract(ctx context.Context, logger *zap.Logger, doc *dossier.Do
 
 	return result, resultErr
 }
+
+// Extract runs all document facters accepting the format on a parsed document
+// file. The logger in the options is given to facters with the plugin name
+// added. Barcodes are decoded from PDF documents first if enabled. Facters
+// are still run without barcodes if scanning fails.
+func (g *Group) Extract(ctx context.Context, opts paperminer.DocumentFacterOptions) (FactsSlice, error) {
+	if g.scanner != nil && opts.Format == paperminer.FormatPDF && opts.Barcodes == nil && g.HasDocumentFacters() {
+		if barcodes, err := g.scanner.Scan(ctx, opts.Path); err != nil {
+			opts.Logger.Error("Scanning barcodes failed", zap.Error(err))
+		} else {
+			opts.Logger.Debug("Barcodes decoded", zap.Any("barcodes", barcodes))
+
+			opts.Barcodes = barcodes
+		}
+	}
+
+	return g.extract(ctx, opts.Logger, func(ctx context.Context, logger *zap.Logger, w *pluginWrapper) (*paperminer.Facts, error) {
+		if !w.acceptsFormat(opts.Format) {
+			return nil, nil
+		}
+
+		opts := opts
+		opts.Logger = logger
+
+		return w.document.DocumentFacts(ctx, opts)
+	})
+}
+
+// ExtractContent runs all content facters on the text content of
+// a document.
+func (g *Group) ExtractContent(ctx context.Context, logger *zap.Logger, info *paperminer.DocumentInfo, content string) (FactsSlice, error) {
+	return g.extract(ctx, logger, func(ctx context.Context, logger *zap.Logger, w *pluginWrapper) (*paperminer.Facts, error) {
+		if w.content == nil {
+			return nil, nil
+		}
+
+		return w.content.ContentFacts(ctx, paperminer.ContentFacterOptions{
+			Logger:  logger,
+			Info:    info,
+			Content: content,
+		})
+	})
+}
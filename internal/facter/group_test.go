@@ -0,0 +1,599 @@
+package facter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/barcode"
+	"github.com/hansmi/paperminer/internal/ref"
+	"github.com/hansmi/paperminer/internal/testutil"
+	"github.com/hansmi/staticplug"
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakePlugin struct {
+	name string
+}
+
+func (p *fakePlugin) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: p.name,
+		New: func() (staticplug.Plugin, error) {
+			return p, nil
+		},
+	}
+}
+
+type fakeDocumentFacter struct {
+	fakePlugin
+}
+
+func (p *fakeDocumentFacter) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: p.name,
+		New: func() (staticplug.Plugin, error) {
+			return p, nil
+		},
+	}
+}
+
+func (p *fakeDocumentFacter) DocumentFacts(context.Context, paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	return &paperminer.Facts{Title: ref.Ref("document " + p.name)}, nil
+}
+
+type fakeFormatsFacter struct {
+	fakeDocumentFacter
+	formats []string
+}
+
+func (p *fakeFormatsFacter) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: p.name,
+		New: func() (staticplug.Plugin, error) {
+			return p, nil
+		},
+	}
+}
+
+func (p *fakeFormatsFacter) Formats() []string {
+	return p.formats
+}
+
+type fakeContentFacter struct {
+	fakePlugin
+}
+
+func (p *fakeContentFacter) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: p.name,
+		New: func() (staticplug.Plugin, error) {
+			return p, nil
+		},
+	}
+}
+
+func (p *fakeContentFacter) ContentFacts(_ context.Context, opts paperminer.ContentFacterOptions) (*paperminer.Facts, error) {
+	return &paperminer.Facts{Title: ref.Ref(opts.Content + " " + p.name)}, nil
+}
+
+func TestGroupFromRegistry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakePlugin{name: "other"})
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "doc"}})
+	reg.MustRegister(&fakeContentFacter{fakePlugin{name: "content"}})
+
+	g, err := GroupFromRegistry(reg, Options{})
+	if err != nil {
+		t.Fatalf("GroupFromRegistry() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"content", "doc"}, g.Names()); diff != "" {
+		t.Errorf("Names() diff (-want +got):\n%s", diff)
+	}
+
+	if !(g.HasContentFacters() && g.HasDocumentFacters()) {
+		t.Errorf("Group is missing facters")
+	}
+
+	if got, err := g.Extract(ctx, paperminer.DocumentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+		Info:   &paperminer.DocumentInfo{},
+		Format: paperminer.FormatPDF,
+	}); err != nil {
+		t.Errorf("Extract() failed: %v", err)
+	} else if diff := cmp.Diff(FactsSlice{
+		{Reporter: ref.Ref("doc"), Title: ref.Ref("document doc")},
+	}, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Extract() diff (-want +got):\n%s", diff)
+	}
+
+	if got, err := g.ExtractContent(ctx, zaptest.NewLogger(t), &paperminer.DocumentInfo{}, "text"); err != nil {
+		t.Errorf("ExtractContent() failed: %v", err)
+	} else if diff := cmp.Diff(FactsSlice{
+		{Reporter: ref.Ref("content"), Title: ref.Ref("text content")},
+	}, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("ExtractContent() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupEmpty(t *testing.T) {
+	g, err := GroupFromRegistry(staticplug.NewRegistry(), Options{})
+	if err != nil {
+		t.Fatalf("GroupFromRegistry() failed: %v", err)
+	}
+
+	if !g.IsEmpty() || g.HasContentFacters() || g.HasDocumentFacters() {
+		t.Errorf("Group from empty registry is not empty")
+	}
+}
+
+func TestGroupFromRegistryOptions(t *testing.T) {
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "first"}})
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "second"}})
+	reg.MustRegister(&fakeContentFacter{fakePlugin{name: "third"}})
+
+	for _, tc := range []struct {
+		name      string
+		opts      Options
+		wantErr   error
+		want      []Info
+		wantNames []string
+	}{
+		{
+			name: "defaults",
+			want: []Info{
+				{Name: "first", Enabled: true},
+				{Name: "second", Enabled: true},
+				{Name: "third", Enabled: true},
+			},
+			wantNames: []string{"first", "second", "third"},
+		},
+		{
+			name: "enable",
+			opts: Options{
+				Enable: []string{"third", "first"},
+			},
+			want: []Info{
+				{Name: "first", Enabled: true},
+				{Name: "second"},
+				{Name: "third", Enabled: true},
+			},
+			wantNames: []string{"first", "third"},
+		},
+		{
+			name: "disable",
+			opts: Options{
+				Enable:  []string{"first", "second"},
+				Disable: []string{"second"},
+			},
+			want: []Info{
+				{Name: "first", Enabled: true},
+				{Name: "second"},
+				{Name: "third"},
+			},
+			wantNames: []string{"first"},
+		},
+		{
+			name: "priority",
+			opts: Options{
+				Priority: map[string]int{"second": 10},
+			},
+			want: []Info{
+				{Name: "first", Enabled: true},
+				{Name: "second", Enabled: true, Priority: ref.Ref(10)},
+				{Name: "third", Enabled: true},
+			},
+			wantNames: []string{"first", "second", "third"},
+		},
+		{
+			name: "suggest",
+			opts: Options{
+				Suggest: []string{"third"},
+			},
+			want: []Info{
+				{Name: "first", Enabled: true},
+				{Name: "second", Enabled: true},
+				{Name: "third", Enabled: true, Suggest: true},
+			},
+			wantNames: []string{"first", "second", "third"},
+		},
+		{
+			name: "unknown enabled",
+			opts: Options{
+				Enable: []string{"missing"},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unknown disabled",
+			opts: Options{
+				Disable: []string{"missing"},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unknown priority",
+			opts: Options{
+				Priority: map[string]int{"missing": 1},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unknown suggest",
+			opts: Options{
+				Suggest: []string{"missing"},
+			},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := GroupFromRegistry(reg, tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tc.want, g.Infos()); diff != "" {
+				t.Errorf("Infos() diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.wantNames, g.Names(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Names() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroupPriorityAndSuggest(t *testing.T) {
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "doc"}})
+
+	g, err := GroupFromRegistry(reg, Options{
+		Priority: map[string]int{"doc": -5},
+		Suggest:  []string{"doc"},
+	})
+	if err != nil {
+		t.Fatalf("GroupFromRegistry() failed: %v", err)
+	}
+
+	got, err := g.Extract(context.Background(), paperminer.DocumentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+		Info:   &paperminer.DocumentInfo{},
+		Format: paperminer.FormatPDF,
+	})
+	if err != nil {
+		t.Errorf("Extract() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(FactsSlice{
+		{Reporter: ref.Ref("doc"), Priority: -5, Suggest: true, Title: ref.Ref("document doc")},
+	}, got); diff != "" {
+		t.Errorf("Extract() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupBarcodesScanFailure(t *testing.T) {
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "doc"}})
+
+	g, err := GroupFromRegistry(reg, Options{
+		Barcodes: true,
+		barcodeRender: func(context.Context, string, barcode.RenderOptions, barcode.PageFunc) error {
+			return errors.New("render failed")
+		},
+	})
+	if err != nil {
+		t.Fatalf("GroupFromRegistry() failed: %v", err)
+	}
+
+	got, err := g.Extract(context.Background(), paperminer.DocumentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+		Info:   &paperminer.DocumentInfo{},
+		Path:   "doc.pdf",
+		Format: paperminer.FormatPDF,
+	})
+	if err != nil {
+		t.Errorf("Extract() failed: %v", err)
+	}
+
+	if diff := cmp.Diff(FactsSlice{
+		{Reporter: ref.Ref("doc"), Title: ref.Ref("document doc")},
+	}, got); diff != "" {
+		t.Errorf("Extract() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGroupExtractFormats(t *testing.T) {
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "pdf"}})
+	reg.MustRegister(&fakeFormatsFacter{
+		fakeDocumentFacter: fakeDocumentFacter{fakePlugin{name: "mail"}},
+		formats:            []string{paperminer.FormatMail},
+	})
+
+	g, err := GroupFromRegistry(reg, Options{})
+	if err != nil {
+		t.Fatalf("GroupFromRegistry() failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		format string
+		want   FactsSlice
+	}{
+		{
+			format: paperminer.FormatPDF,
+			want:   FactsSlice{{Reporter: ref.Ref("pdf"), Title: ref.Ref("document pdf")}},
+		},
+		{
+			format: paperminer.FormatMail,
+			want:   FactsSlice{{Reporter: ref.Ref("mail"), Title: ref.Ref("document mail")}},
+		},
+		{format: paperminer.FormatText},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			got, err := g.Extract(context.Background(), paperminer.DocumentFacterOptions{
+				Logger: zaptest.NewLogger(t),
+				Info:   &paperminer.DocumentInfo{},
+				Format: tc.format,
+			})
+			if err != nil {
+				t.Errorf("Extract() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Extract() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroupRulesFile(t *testing.T) {
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "doc"}})
+
+	valid := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "rules.yaml"), `
+rules:
+  - name: acme
+    match:
+      text: ACME
+    facts:
+      correspondent: ACME
+`)
+
+	invalid := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "rules.yaml"), `
+rules:
+  - name: acme
+    match: {}
+    facts:
+      correspondent: ACME
+`)
+
+	for _, tc := range []struct {
+		name      string
+		opts      Options
+		wantErr   error
+		wantNames []string
+	}{
+		{
+			name: "rules",
+			opts: Options{
+				RulesFile: valid,
+			},
+			wantNames: []string{"doc", "rules"},
+		},
+		{
+			name: "rules disabled",
+			opts: Options{
+				RulesFile: valid,
+				Disable:   []string{"rules"},
+			},
+			wantNames: []string{"doc"},
+		},
+		{
+			name: "invalid rules",
+			opts: Options{
+				RulesFile: invalid,
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "missing file",
+			opts: Options{
+				RulesFile: filepath.Join(t.TempDir(), "missing.yaml"),
+			},
+			wantErr: os.ErrNotExist,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := GroupFromRegistry(reg, tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tc.wantNames, g.Names()); diff != "" {
+				t.Errorf("Names() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroupScriptFiles(t *testing.T) {
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "doc"}})
+
+	dir := t.TempDir()
+
+	valid := testutil.MustWriteFileString(t, filepath.Join(dir, "acme.star"), `
+def facts(doc):
+    if "ACME" in doc.content:
+        return {"correspondent": "ACME"}
+`)
+
+	other := testutil.MustWriteFileString(t, filepath.Join(dir, "other.star"), `
+def facts(doc):
+    return None
+`)
+
+	invalid := testutil.MustWriteFileString(t, filepath.Join(dir, "invalid.star"), "def facts(doc)\n")
+
+	for _, tc := range []struct {
+		name      string
+		opts      Options
+		wantErr   error
+		wantNames []string
+	}{
+		{
+			name: "scripts",
+			opts: Options{
+				ScriptFiles: []string{valid, other},
+			},
+			wantNames: []string{"doc", "script:acme", "script:other"},
+		},
+		{
+			name: "script disabled",
+			opts: Options{
+				ScriptFiles: []string{valid, other},
+				Disable:     []string{"script:acme"},
+			},
+			wantNames: []string{"doc", "script:other"},
+		},
+		{
+			name: "duplicate name",
+			opts: Options{
+				ScriptFiles: []string{valid, filepath.Join(t.TempDir(), "acme.star")},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "invalid script",
+			opts: Options{
+				ScriptFiles: []string{invalid},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "missing file",
+			opts: Options{
+				ScriptFiles: []string{filepath.Join(dir, "missing.star")},
+			},
+			wantErr: os.ErrNotExist,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := GroupFromRegistry(reg, tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tc.wantNames, g.Names()); diff != "" {
+				t.Errorf("Names() diff (-want +got):\n%s", diff)
+			}
+
+			if !g.HasContentFacters() {
+				t.Errorf("HasContentFacters() = false, want true")
+			}
+		})
+	}
+}
+
+func TestGroupBarcodes(t *testing.T) {
+	const payload = "BCD\n002\n1\nSCT\n\nRed Cross\nBE72000000001616\nEUR25"
+
+	m, err := qrcode.NewQRCodeWriter().Encode(payload, gozxing.BarcodeFormat_QR_CODE, 300, 300, nil)
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeFormatsFacter{
+		fakeDocumentFacter: fakeDocumentFacter{fakePlugin{name: "doc"}},
+		formats:            []string{paperminer.FormatPDF, paperminer.FormatText},
+	})
+
+	var rendered []string
+
+	g, err := GroupFromRegistry(reg, Options{
+		Barcodes:           true,
+		BarcodeAmountField: "Amount",
+		barcodeRender: func(_ context.Context, path string, _ barcode.RenderOptions, fn barcode.PageFunc) error {
+			rendered = append(rendered, path)
+
+			return fn(1, m)
+		},
+	})
+	if err != nil {
+		t.Fatalf("GroupFromRegistry() failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"doc", "barcodes"}, g.Names()); diff != "" {
+		t.Errorf("Names() diff (-want +got):\n%s", diff)
+	}
+
+	for _, format := range []string{paperminer.FormatText, paperminer.FormatPDF} {
+		got, err := g.Extract(context.Background(), paperminer.DocumentFacterOptions{
+			Logger: zaptest.NewLogger(t),
+			Info:   &paperminer.DocumentInfo{},
+			Path:   "doc." + format,
+			Format: format,
+		})
+		if err != nil {
+			t.Errorf("Extract() failed: %v", err)
+		}
+
+		want := FactsSlice{
+			{Reporter: ref.Ref("doc"), Title: ref.Ref("document doc")},
+		}
+
+		if format == paperminer.FormatPDF {
+			want = append(want, &paperminer.Facts{
+				Reporter:      ref.Ref("barcodes"),
+				Correspondent: ref.Ref("Red Cross"),
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					"Amount": {Monetary: &paperminer.MonetaryValue{Currency: "EUR", Amount: 25}},
+				},
+			})
+		}
+
+		if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b *paperminer.Facts) bool {
+			return *a.Reporter < *b.Reporter
+		})); diff != "" {
+			t.Errorf("Extract() diff (-want +got):\n%s", diff)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"doc." + paperminer.FormatPDF}, rendered); diff != "" {
+		t.Errorf("Rendered documents diff (-want +got):\n%s", diff)
+	}
+
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "barcodes"}})
+
+	if _, err := GroupFromRegistry(reg, Options{Barcodes: true}); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("GroupFromRegistry() with reserved name returned %v, want invalid", err)
+	}
+}
@@ -0,0 +1,184 @@
+package facter
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/hansmi/paperminer/internal/barcode"
+	"github.com/hansmi/paperminer/internal/barcodefacter"
+	"github.com/hansmi/paperminer/internal/kpflagvalue"
+	"github.com/hansmi/paperminer/internal/rulefacter"
+	"github.com/hansmi/paperminer/internal/scriptfacter"
+)
+
+// Options control which facters are used.
+type Options struct {
+	// Names of facters to use. All facters are enabled if empty.
+	Enable []string
+
+	// Names of facters to never use. Takes precedence over Enable.
+	Disable []string
+
+	// Priority for facts reported by a facter, overriding the priority set
+	// by the facter itself (see [paperminer.Facts.Priority]).
+	Priority map[string]int
+
+	// Names of facters whose facts are proposed for review instead of
+	// applied (see [paperminer.Facts.Suggest]).
+	Suggest []string
+
+	// Path to a YAML file with rules for the built-in rule facter. The rule
+	// facter is only used if set.
+	RulesFile string
+
+	// Interval for checking the rule file and scripts for changes. Zero
+	// disables checking; reloads can still be triggered via SIGHUP.
+	RulesReloadInterval time.Duration
+
+	// Paths to Starlark scripts. Each script becomes a facter named after
+	// the file (see [scriptfacter.Name]).
+	ScriptFiles []string
+
+	// Limits for evaluating a document with a script.
+	ScriptTimeout  time.Duration
+	ScriptMaxSteps uint64
+
+	// Decode barcodes and QR codes on the pages of PDF documents and give
+	// them to document facters. Enables the built-in barcode facter. Pages
+	// are rendered using the pdftoppm program, which must be installed.
+	Barcodes bool
+
+	// Resolution for rendering pages and the maximum number of pages to scan
+	// for barcodes (zero for all).
+	BarcodeDPI      int
+	BarcodeMaxPages int
+
+	// Custom fields for the amount and the reference of payment codes.
+	BarcodeAmountField    string
+	BarcodeReferenceField string
+
+	// Function for rendering pages. Overridden in tests.
+	barcodeRender barcode.RenderFunc
+}
+
+func (o *Options) RegisterFlags(app *kingpin.Application) {
+	kpflagvalue.CommaSeparatedStringsVar(
+		app.Flag("facter_enable", "Names of facters to use (comma-separated). All facters are used if empty.").
+			PlaceHolder("NAME"),
+		&o.Enable)
+
+	kpflagvalue.CommaSeparatedStringsVar(
+		app.Flag("facter_disable", "Names of facters to never use (comma-separated).").
+			PlaceHolder("NAME"),
+		&o.Disable)
+
+	kpflagvalue.CommaSeparatedIntMapVar(
+		app.Flag("facter_priority", "Priority for facts from a facter, overriding the facter's own (comma-separated). Facts with a higher priority win conflicts.").
+			PlaceHolder("NAME=PRIORITY"),
+		&o.Priority)
+
+	kpflagvalue.CommaSeparatedStringsVar(
+		app.Flag("facter_suggest", "Names of facters whose facts are proposed for review instead of applied (comma-separated).").
+			PlaceHolder("NAME"),
+		&o.Suggest)
+
+	app.Flag("facter_rules_file", fmt.Sprintf("YAML file with match rules and the facts to set. Enables the built-in %q facter.", rulefacter.Name)).
+		PlaceHolder("PATH").
+		StringVar(&o.RulesFile)
+
+	app.Flag("facter_rules_reload_interval", "Interval for checking the rules file and scripts for changes. Zero disables checking. Facters are also reloaded on SIGHUP.").
+		Default("30s").
+		DurationVar(&o.RulesReloadInterval)
+
+	app.Flag("facter_script", fmt.Sprintf("Starlark script defining a facts function. Each script is a facter named %q followed by the file name without extension. Can be given multiple times.", scriptfacter.NamePrefix)).
+		PlaceHolder("PATH").
+		StringsVar(&o.ScriptFiles)
+
+	app.Flag("facter_script_timeout", "Maximum amount of time for evaluating a document with a script.").
+		Default("10s").
+		DurationVar(&o.ScriptTimeout)
+
+	app.Flag("facter_script_max_steps", "Maximum number of execution steps for evaluating a document with a script.").
+		Default("10000000").
+		Uint64Var(&o.ScriptMaxSteps)
+
+	app.Flag("facter_barcodes", fmt.Sprintf("Decode barcodes and QR codes on the pages of PDF documents. Requires the pdftoppm program from Poppler. Enables the built-in %q facter reporting facts from payment codes.", barcodefacter.Name)).
+		BoolVar(&o.Barcodes)
+
+	app.Flag("facter_barcodes_dpi", "Resolution for rendering pages when decoding barcodes.").
+		Default("200").
+		IntVar(&o.BarcodeDPI)
+
+	app.Flag("facter_barcodes_max_pages", "Maximum number of pages, starting with the first, to scan for barcodes. Zero scans all pages.").
+		Default("10").
+		IntVar(&o.BarcodeMaxPages)
+
+	app.Flag("facter_barcodes_amount_field", "Monetary custom field for the amount of payment codes.").
+		PlaceHolder("NAME").
+		StringVar(&o.BarcodeAmountField)
+
+	app.Flag("facter_barcodes_reference_field", "String custom field for the reference of payment codes.").
+		PlaceHolder("NAME").
+		StringVar(&o.BarcodeReferenceField)
+}
+
+// validate checks whether all facters named in the options exist.
+func (o *Options) validate(names []string) error {
+	check := func(kind, name string) error {
+		if !slices.Contains(names, name) {
+			return fmt.Errorf("%w: %s of unknown facter %q", os.ErrInvalid, kind, name)
+		}
+
+		return nil
+	}
+
+	for _, name := range o.Enable {
+		if err := check("enabling", name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range o.Disable {
+		if err := check("disabling", name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range o.Suggest {
+		if err := check("suggesting", name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(o.Priority)) {
+		if err := check("priority", name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *Options) enabled(name string) bool {
+	if slices.Contains(o.Disable, name) {
+		return false
+	}
+
+	return len(o.Enable) == 0 || slices.Contains(o.Enable, name)
+}
+
+func (o *Options) suggest(name string) bool {
+	return slices.Contains(o.Suggest, name)
+}
+
+func (o *Options) priority(name string) *int {
+	if p, ok := o.Priority[name]; ok {
+		return &p
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package facter
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hansmi/staticplug"
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+type ReloaderOptions struct {
+	Logger   *zap.Logger
+	Registry *staticplug.Registry
+	Options  Options
+
+	// Registry for reload metrics. Metrics are not registered if nil.
+	Metrics prometheus.Registerer
+
+	clock   clockwork.Clock
+	signals chan os.Signal
+}
+
+// Reloader builds the facter group from the registry and file-based facter
+// definitions. The group is rebuilt on SIGHUP and when the rule file or
+// a script changes.
+// Callers retrieve the current group once per document, so a new group only
+// takes effect between documents.
+type Reloader struct {
+	opts ReloaderOptions
+
+	current atomic.Pointer[Group]
+
+	mu sync.Mutex
+
+	// Hash of the rule file and script content at the last reload attempt.
+	fileHash [sha256.Size]byte
+
+	reloads    *prometheus.CounterVec
+	lastReload *prometheus.GaugeVec
+}
+
+// NewReloader builds the initial facter group. Errors are returned as-is.
+func NewReloader(opts ReloaderOptions) (*Reloader, error) {
+	if opts.clock == nil {
+		opts.clock = clockwork.NewRealClock()
+	}
+
+	r := &Reloader{
+		opts: opts,
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "facter_reloads_total",
+			Help: "Number of facter reloads by result.",
+		}, []string{"result"}),
+		lastReload: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "facter_last_reload_timestamp_seconds",
+			Help: "Time of the last facter reload by result.",
+		}, []string{"result"}),
+	}
+
+	for _, result := range []string{"success", "failure"} {
+		r.reloads.WithLabelValues(result)
+	}
+
+	r.fileHash = r.hashFiles()
+
+	g, err := GroupFromRegistry(opts.Registry, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.current.Store(g)
+	r.lastReload.WithLabelValues("success").Set(float64(opts.clock.Now().UnixNano()) / 1e9)
+
+	if opts.Metrics != nil {
+		if err := opts.Metrics.Register(r.reloads); err != nil {
+			return nil, err
+		}
+
+		if err := opts.Metrics.Register(r.lastReload); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Group returns the current facter group.
+func (r *Reloader) Group() *Group {
+	return r.current.Load()
+}
+
+// files returns the paths of all files with facter definitions.
+func (r *Reloader) files() []string {
+	var files []string
+
+	if r.opts.Options.RulesFile != "" {
+		files = append(files, r.opts.Options.RulesFile)
+	}
+
+	return append(files, r.opts.Options.ScriptFiles...)
+}
+
+// hashFiles returns a combined hash of the content of all files with facter
+// definitions. Unreadable files are hashed as if they were empty.
+func (r *Reloader) hashFiles() [sha256.Size]byte {
+	h := sha256.New()
+
+	for _, path := range r.files() {
+		data, _ := os.ReadFile(path)
+
+		fileHash := sha256.Sum256(data)
+
+		h.Write(fileHash[:])
+	}
+
+	return [sha256.Size]byte(h.Sum(nil))
+}
+
+// Reload rebuilds the facter group. The previous group is retained on
+// failure. Failures are counted in the reload metrics.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reloadLocked()
+}
+
+func (r *Reloader) reloadLocked() error {
+	r.fileHash = r.hashFiles()
+
+	g, err := GroupFromRegistry(r.opts.Registry, r.opts.Options)
+
+	result := "success"
+
+	if err != nil {
+		result = "failure"
+	} else {
+		r.current.Store(g)
+
+		r.opts.Logger.Info("Facters reloaded", zap.Strings("facters", g.Names()))
+	}
+
+	r.reloads.WithLabelValues(result).Inc()
+	r.lastReload.WithLabelValues(result).Set(float64(r.opts.clock.Now().UnixNano()) / 1e9)
+
+	return err
+}
+
+// reloadIfChanged reloads the facter group if the content of the rule file or
+// a script differs from the last reload attempt.
+func (r *Reloader) reloadIfChanged() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.hashFiles() == r.fileHash {
+		return nil
+	}
+
+	return r.reloadLocked()
+}
+
+// Run reloads the facter group on SIGHUP and, if configured, when the rule
+// file or a script changes. Returns when the context is canceled.
+func (r *Reloader) Run(ctx context.Context) error {
+	signals := r.opts.signals
+
+	if signals == nil {
+		signals = make(chan os.Signal, 1)
+
+		signal.Notify(signals, unix.SIGHUP)
+		defer signal.Stop(signals)
+	}
+
+	var tick <-chan time.Time
+
+	if interval := r.opts.Options.RulesReloadInterval; len(r.files()) > 0 && interval > 0 {
+		ticker := r.opts.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.Chan()
+	}
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-signals:
+			r.opts.Logger.Info("Reloading facters on signal")
+			err = r.Reload()
+
+		case <-tick:
+			err = r.reloadIfChanged()
+		}
+
+		if err != nil {
+			r.opts.Logger.Error("Reloading facters failed, keeping previous facters", zap.Error(err))
+		}
+	}
+}
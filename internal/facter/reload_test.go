@@ -0,0 +1,198 @@
+package facter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hansmi/paperminer/internal/testutil"
+	"github.com/hansmi/staticplug"
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const testRules = `
+rules:
+  - name: acme
+    match:
+      text: ACME
+    facts:
+      correspondent: ACME
+`
+
+func TestReloader(t *testing.T) {
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "rules.yaml"), testRules)
+
+	reg := staticplug.NewRegistry()
+	reg.MustRegister(&fakeDocumentFacter{fakePlugin{name: "doc"}})
+
+	metrics := prometheus.NewPedanticRegistry()
+
+	r, err := NewReloader(ReloaderOptions{
+		Logger:   zaptest.NewLogger(t),
+		Registry: reg,
+		Options: Options{
+			RulesFile: path,
+		},
+		Metrics: metrics,
+		clock:   clockwork.NewFakeClockAt(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("NewReloader() failed: %v", err)
+	}
+
+	initial := r.Group()
+
+	if diff := cmp.Diff([]string{"doc", "rules"}, initial.Names()); diff != "" {
+		t.Errorf("Names() diff (-want +got):\n%s", diff)
+	}
+
+	testutil.MustWriteFileString(t, path, "rules: [{name: broken}]")
+
+	if err := r.Reload(); err == nil {
+		t.Errorf("Reload() succeeded with invalid rules")
+	}
+
+	if r.Group() != initial {
+		t.Errorf("Group changed after failed reload")
+	}
+
+	testutil.MustWriteFileString(t, path, testRules)
+
+	if err := r.Reload(); err != nil {
+		t.Errorf("Reload() failed: %v", err)
+	}
+
+	if r.Group() == initial {
+		t.Errorf("Group not replaced after reload")
+	}
+
+	if got := promtestutil.ToFloat64(r.reloads.WithLabelValues("success")); got != 1 {
+		t.Errorf("Got %v successful reloads, want 1", got)
+	}
+
+	if got := promtestutil.ToFloat64(r.reloads.WithLabelValues("failure")); got != 1 {
+		t.Errorf("Got %v failed reloads, want 1", got)
+	}
+
+	if got := promtestutil.ToFloat64(r.lastReload.WithLabelValues("failure")); got != 1577836800 {
+		t.Errorf("Got last failure timestamp %v", got)
+	}
+
+	if count, err := promtestutil.GatherAndCount(metrics); err != nil {
+		t.Errorf("Gathering metrics failed: %v", err)
+	} else if count != 4 {
+		t.Errorf("Got %d metrics, want 4", count)
+	}
+}
+
+func TestReloaderInitialFailure(t *testing.T) {
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "rules.yaml"), "rules: [{name: broken}]")
+
+	if _, err := NewReloader(ReloaderOptions{
+		Logger:   zaptest.NewLogger(t),
+		Registry: staticplug.NewRegistry(),
+		Options: Options{
+			RulesFile: path,
+		},
+	}); err == nil {
+		t.Errorf("NewReloader() succeeded with invalid rules")
+	}
+}
+
+func TestReloaderRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "rules.yaml"), testRules)
+
+	clock := clockwork.NewFakeClock()
+	signals := make(chan os.Signal)
+
+	core, logs := observer.New(zap.InfoLevel)
+
+	r, err := NewReloader(ReloaderOptions{
+		Logger:   zap.New(core),
+		Registry: staticplug.NewRegistry(),
+		Options: Options{
+			RulesFile:           path,
+			RulesReloadInterval: time.Minute,
+		},
+		clock:   clock,
+		signals: signals,
+	})
+	if err != nil {
+		t.Fatalf("NewReloader() failed: %v", err)
+	}
+
+	done := make(chan error)
+
+	go func() {
+		done <- r.Run(ctx)
+	}()
+
+	waitForResult := func(result string, want float64) {
+		t.Helper()
+
+		for deadline := time.Now().Add(10 * time.Second); ; {
+			if promtestutil.ToFloat64(r.reloads.WithLabelValues(result)) == want {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("Timeout while waiting for %v reloads with result %q", want, result)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitForReloads := func(want float64) {
+		t.Helper()
+		waitForResult("success", want)
+	}
+
+	// Reload on signal.
+	signals <- os.Interrupt
+	waitForReloads(1)
+
+	if err := clock.BlockUntilContext(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged file.
+	clock.Advance(time.Minute)
+	signals <- os.Interrupt
+	waitForReloads(2)
+
+	// Changed file.
+	testutil.MustWriteFileString(t, path, testRules+"\n# changed\n")
+	clock.Advance(time.Minute)
+	waitForReloads(3)
+
+	// Invalid file.
+	testutil.MustWriteFileString(t, path, "rules: [{name: broken}]")
+	clock.Advance(time.Minute)
+	waitForResult("failure", 1)
+
+	if r.Group() == nil {
+		t.Errorf("Previous facters weren't retained")
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() failed: %v", err)
+	}
+
+	if got := logs.FilterMessage("Reloading facters failed, keeping previous facters").Len(); got != 1 {
+		t.Errorf("Logged %d reload failures, want 1", got)
+	}
+}
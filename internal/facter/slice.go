@@ -1,25 +1,237 @@
 package facter
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hansmi/paperminer"
+	"go.uber.org/multierr"
 )
 
+var errConflict = errors.New("conflicting facts")
+
 type FactsSlice []*paperminer.Facts
 
+func reporterName(f *paperminer.Facts) string {
+	if f.Reporter == nil {
+		return "<unknown>"
+	}
+
+	return *f.Reporter
+}
+
+// factsMerger combines facts field by field. Candidates must be ordered by
+// descending priority.
+type factsMerger struct {
+	candidates FactsSlice
+	reporters  []string
+	suggest    bool
+	force      []string
+	err        error
+}
+
+func (m *factsMerger) contributed(f *paperminer.Facts) {
+	if name := reporterName(f); !slices.Contains(m.reporters, name) {
+		m.reporters = append(m.reporters, name)
+	}
+
+	if f.Suggest {
+		m.suggest = true
+	}
+
+	for _, name := range f.Force {
+		if !slices.Contains(m.force, name) {
+			m.force = append(m.force, name)
+		}
+	}
+}
+
+func (m *factsMerger) conflict(field string, a *paperminer.Facts, aValue any, b *paperminer.Facts, bValue any) {
+	multierr.AppendInto(&m.err, fmt.Errorf("%w: %s is %v according to %q, but %v according to %q (priority %d)",
+		errConflict, field, aValue, reporterName(a), bValue, reporterName(b), a.Priority))
+}
+
+func mergeValue[T any](m *factsMerger, field string, get func(*paperminer.Facts) *T, equal func(T, T) bool) *T {
+	var result *T
+	var source *paperminer.Facts
+
+	for _, f := range m.candidates {
+		value := get(f)
+
+		if value == nil {
+			continue
+		}
+
+		if source == nil {
+			result = value
+			source = f
+		} else if equal(*result, *value) {
+			// Agreement
+		} else if f.Priority < source.Priority {
+			// Overruled by facts with a higher priority
+			continue
+		} else {
+			m.conflict(field, source, *result, f, *value)
+			continue
+		}
+
+		m.contributed(f)
+	}
+
+	return result
+}
+
+func (m *factsMerger) mergeTags() (setTags, unsetTags []string) {
+	type decision struct {
+		set    bool
+		source *paperminer.Facts
+	}
+
+	var order []string
+
+	decisions := map[string]decision{}
+
+	for _, f := range m.candidates {
+		for _, i := range []struct {
+			names []string
+			set   bool
+		}{
+			{f.SetTags, true},
+			{f.UnsetTags, false},
+		} {
+			for _, name := range i.names {
+				if d, ok := decisions[name]; !ok {
+					decisions[name] = decision{i.set, f}
+					order = append(order, name)
+				} else if d.set != i.set && f.Priority >= d.source.Priority {
+					m.conflict(fmt.Sprintf("tag %q", name), d.source, d.set, f, i.set)
+					continue
+				} else if d.set != i.set {
+					continue
+				}
+
+				m.contributed(f)
+			}
+		}
+	}
+
+	for _, name := range order {
+		if decisions[name].set {
+			setTags = append(setTags, name)
+		} else {
+			unsetTags = append(unsetTags, name)
+		}
+	}
+
+	return setTags, unsetTags
+}
+
+func (m *factsMerger) mergeCustomFields() map[string]paperminer.CustomFieldValue {
+	var names []string
+
+	for _, f := range m.candidates {
+		for name := range f.CustomFields {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	slices.Sort(names)
+
+	result := map[string]paperminer.CustomFieldValue{}
+
+	for _, name := range names {
+		value := mergeValue(m, fmt.Sprintf("custom field %q", name), func(f *paperminer.Facts) *paperminer.CustomFieldValue {
+			if v, ok := f.CustomFields[name]; ok {
+				return &v
+			}
+
+			return nil
+		}, func(a, b paperminer.CustomFieldValue) bool {
+			return reflect.DeepEqual(a, b)
+		})
+
+		result[name] = *value
+	}
+
+	return result
+}
+
+func equalComparable[T comparable](a, b T) bool {
+	return a == b
+}
+
+func (m *factsMerger) merge() (*paperminer.Facts, error) {
+	result := &paperminer.Facts{
+		Priority: m.candidates[0].Priority,
+	}
+
+	for _, i := range []struct {
+		field string
+		dst   **string
+		get   func(*paperminer.Facts) *string
+	}{
+		{"title", &result.Title, func(f *paperminer.Facts) *string { return f.Title }},
+		{"document type", &result.DocumentType, func(f *paperminer.Facts) *string { return f.DocumentType }},
+		{"correspondent", &result.Correspondent, func(f *paperminer.Facts) *string { return f.Correspondent }},
+		{"storage path", &result.StoragePath, func(f *paperminer.Facts) *string { return f.StoragePath }},
+	} {
+		*i.dst = mergeValue(m, i.field, i.get, equalComparable[string])
+	}
+
+	result.Created = mergeValue(m, "created", func(f *paperminer.Facts) *time.Time {
+		return f.Created
+	}, time.Time.Equal)
+
+	result.SetTags, result.UnsetTags = m.mergeTags()
+	result.CustomFields = m.mergeCustomFields()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if len(m.reporters) > 0 {
+		reporter := strings.Join(m.reporters, ", ")
+		result.Reporter = &reporter
+	}
+
+	result.Suggest = m.suggest
+	result.Force = m.force
+
+	return result, nil
+}
+
+// Best selects the facts to apply among all candidates. Multiple candidates
+// are merged field by field. Differing values for the same field are resolved
+// in favour of the facts with the highest priority. An error naming the
+// reporters is returned if the highest-priority values conflict.
 func (s FactsSlice) Best() (*paperminer.Facts, error) {
 	switch len(s) {
 	case 0:
 		return nil, nil
-
 	case 1:
 		return s[0], nil
 	}
 
-	// TODO: Add some sort of ranking and select the best facts if there are
-	// multiple candidates. Maybe over all document variants (archived,
-	// original). Facts can also be given a number as a "relative weight".
+	candidates := slices.Clone(s)
+
+	slices.SortStableFunc(candidates, func(a, b *paperminer.Facts) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+
+	m := factsMerger{
+		candidates: candidates,
+	}
 
-	return nil, fmt.Errorf("unable to select among %d facts: %v", len(s), s)
+	return m.merge()
 }
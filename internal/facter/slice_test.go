@@ -2,6 +2,7 @@ package facter
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -10,6 +11,8 @@ import (
 )
 
 func TestFactsSliceBest(t *testing.T) {
+	created := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+
 	for _, tc := range []struct {
 		name    string
 		s       FactsSlice
@@ -31,9 +34,147 @@ func TestFactsSliceBest(t *testing.T) {
 			},
 		},
 		{
-			name:    "multiple",
-			s:       []*paperminer.Facts{{}, {}, {}},
-			wantErr: cmpopts.AnyError,
+			name: "multiple empty",
+			s:    []*paperminer.Facts{{}, {}, {}},
+			want: &paperminer.Facts{},
+		},
+		{
+			name: "merge without conflicts",
+			s: []*paperminer.Facts{
+				{
+					Reporter: ref.Ref("generic"),
+					Title:    ref.Ref("Bank statement"),
+					Created:  ref.Ref(created),
+					SetTags:  []string{"bank"},
+				},
+				{
+					Reporter:      ref.Ref("specific"),
+					Created:       ref.Ref(created.In(time.FixedZone("", 3600))),
+					Correspondent: ref.Ref("Bank"),
+					SetTags:       []string{"bank", "statement"},
+					UnsetTags:     []string{"inbox"},
+					CustomFields: map[string]paperminer.CustomFieldValue{
+						"iban": {String: ref.Ref("CH00")},
+					},
+				},
+			},
+			want: &paperminer.Facts{
+				Reporter:      ref.Ref("generic, specific"),
+				Title:         ref.Ref("Bank statement"),
+				Created:       ref.Ref(created),
+				Correspondent: ref.Ref("Bank"),
+				SetTags:       []string{"bank", "statement"},
+				UnsetTags:     []string{"inbox"},
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					"iban": {String: ref.Ref("CH00")},
+				},
+			},
+		},
+		{
+			name: "conflict resolved by priority",
+			s: []*paperminer.Facts{
+				{
+					Reporter:  ref.Ref("generic"),
+					Title:     ref.Ref("Bank statement"),
+					UnsetTags: []string{"finance"},
+					CustomFields: map[string]paperminer.CustomFieldValue{
+						"iban": {String: ref.Ref("wrong")},
+					},
+				},
+				{
+					Reporter: ref.Ref("specific"),
+					Priority: 10,
+					Title:    ref.Ref("Account statement"),
+					SetTags:  []string{"finance"},
+					CustomFields: map[string]paperminer.CustomFieldValue{
+						"iban": {String: ref.Ref("CH00")},
+					},
+				},
+			},
+			want: &paperminer.Facts{
+				Reporter: ref.Ref("specific"),
+				Priority: 10,
+				Title:    ref.Ref("Account statement"),
+				SetTags:  []string{"finance"},
+				CustomFields: map[string]paperminer.CustomFieldValue{
+					"iban": {String: ref.Ref("CH00")},
+				},
+			},
+		},
+		{
+			name: "suggestion",
+			s: []*paperminer.Facts{
+				{Reporter: ref.Ref("trusted"), Title: ref.Ref("Invoice")},
+				{Reporter: ref.Ref("new"), Suggest: true, Correspondent: ref.Ref("ACME")},
+			},
+			want: &paperminer.Facts{
+				Reporter:      ref.Ref("trusted, new"),
+				Suggest:       true,
+				Title:         ref.Ref("Invoice"),
+				Correspondent: ref.Ref("ACME"),
+			},
+		},
+		{
+			name: "overruled suggestion",
+			s: []*paperminer.Facts{
+				{Reporter: ref.Ref("trusted"), Priority: 1, Title: ref.Ref("Invoice")},
+				{Reporter: ref.Ref("new"), Suggest: true, Title: ref.Ref("Receipt")},
+			},
+			want: &paperminer.Facts{
+				Reporter: ref.Ref("trusted"),
+				Priority: 1,
+				Title:    ref.Ref("Invoice"),
+			},
+		},
+		{
+			name: "forced fields",
+			s: []*paperminer.Facts{
+				{Reporter: ref.Ref("first"), Priority: 1, Force: []string{paperminer.FieldTitle}, Title: ref.Ref("Invoice")},
+				{Reporter: ref.Ref("second"), Force: []string{paperminer.FieldCorrespondent, paperminer.FieldTitle}, Correspondent: ref.Ref("ACME")},
+				{Reporter: ref.Ref("overruled"), Force: []string{paperminer.FieldCreated}, Title: ref.Ref("Receipt")},
+			},
+			want: &paperminer.Facts{
+				Reporter:      ref.Ref("first, second"),
+				Priority:      1,
+				Force:         []string{paperminer.FieldTitle, paperminer.FieldCorrespondent},
+				Title:         ref.Ref("Invoice"),
+				Correspondent: ref.Ref("ACME"),
+			},
+		},
+		{
+			name: "conflict with same priority",
+			s: []*paperminer.Facts{
+				{Reporter: ref.Ref("first"), Title: ref.Ref("a")},
+				{Reporter: ref.Ref("second"), Title: ref.Ref("b")},
+			},
+			wantErr: errConflict,
+		},
+		{
+			name: "tag conflict with same priority",
+			s: []*paperminer.Facts{
+				{Reporter: ref.Ref("first"), Priority: 3, SetTags: []string{"x"}},
+				{Reporter: ref.Ref("second"), Priority: 3, UnsetTags: []string{"x"}},
+				{Reporter: ref.Ref("third"), Priority: 1, UnsetTags: []string{"x"}},
+			},
+			wantErr: errConflict,
+		},
+		{
+			name: "custom field conflict with same priority",
+			s: []*paperminer.Facts{
+				{
+					Reporter: ref.Ref("first"),
+					CustomFields: map[string]paperminer.CustomFieldValue{
+						"total": {Integer: ref.Ref[int64](1)},
+					},
+				},
+				{
+					Reporter: ref.Ref("second"),
+					CustomFields: map[string]paperminer.CustomFieldValue{
+						"total": {Integer: ref.Ref[int64](2)},
+					},
+				},
+			},
+			wantErr: errConflict,
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -1,15 +1,50 @@
 package facter
 
-import "github.com/hansmi/paperminer"
+import (
+	"slices"
+
+	"github.com/hansmi/paperminer"
+)
 
 type pluginWrapper struct {
 	name string
-	inst paperminer.DocumentFacter
+
+	// At least one of the facter interfaces is implemented.
+	document paperminer.DocumentFacter
+	content  paperminer.ContentFacter
+
+	// Formats accepted by the document facter.
+	formats []string
+
+	// Priority overriding the priority of reported facts, if any.
+	priority *int
+
+	// Whether reported facts are only suggested.
+	suggest bool
 }
 
-func newPluginWrapper(df paperminer.DocumentFacter) *pluginWrapper {
-	return &pluginWrapper{
-		name: df.PluginInfo().Name,
-		inst: df,
+func newPluginWrapper(inst any) *pluginWrapper {
+	w := &pluginWrapper{}
+	w.document, _ = inst.(paperminer.DocumentFacter)
+	w.content, _ = inst.(paperminer.ContentFacter)
+
+	switch {
+	case w.document != nil:
+		w.name = w.document.PluginInfo().Name
+		w.formats = []string{paperminer.FormatPDF}
+
+		if f, ok := w.document.(paperminer.FormatsFacter); ok {
+			w.formats = f.Formats()
+		}
+	case w.content != nil:
+		w.name = w.content.PluginInfo().Name
 	}
+
+	return w
+}
+
+// acceptsFormat returns whether the document facter accepts files in
+// a format.
+func (w *pluginWrapper) acceptsFormat(format string) bool {
+	return w.document != nil && slices.Contains(w.formats, format)
 }
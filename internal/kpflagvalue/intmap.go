@@ -0,0 +1,53 @@
+package kpflagvalue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+)
+
+type commaSeparatedIntMap map[string]int
+
+var _ kingpin.Value = (*commaSeparatedIntMap)(nil)
+
+func (m *commaSeparatedIntMap) String() string {
+	return fmt.Sprint(map[string]int(*m))
+}
+
+func (m *commaSeparatedIntMap) IsCumulative() bool {
+	return true
+}
+
+func (m *commaSeparatedIntMap) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		key, valueStr, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("expected KEY=VALUE, got %q", part)
+		}
+
+		num, err := strconv.Atoi(strings.TrimSpace(valueStr))
+		if err != nil {
+			return fmt.Errorf("value for %q: %w", key, err)
+		}
+
+		if *m == nil {
+			*m = commaSeparatedIntMap{}
+		}
+
+		(*m)[strings.TrimSpace(key)] = num
+	}
+
+	return nil
+}
+
+// CommaSeparatedIntMapVar parses comma-separated "KEY=VALUE" pairs with
+// integer values.
+func CommaSeparatedIntMapVar(t kingpin.Settings, target *map[string]int) {
+	t.SetValue((*commaSeparatedIntMap)(target))
+}
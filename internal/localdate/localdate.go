@@ -0,0 +1,42 @@
+// Package localdate parses dates containing localized month names.
+package localdate
+
+import (
+	"strings"
+	"time"
+)
+
+// Month names by locale in calendar order.
+var monthNames = map[string][12]string{
+	"de": {"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	"fr": {"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	"it": {"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	"nl": {"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+}
+
+// ValidLocale returns whether a locale is supported. The empty locale stands
+// for English.
+func ValidLocale(locale string) bool {
+	_, ok := monthNames[locale]
+
+	return ok || locale == ""
+}
+
+// Parse parses a date using [time.Parse] after replacing a localized month
+// name with its English equivalent. Only full month names are translated.
+func Parse(layout, locale, value string) (time.Time, error) {
+	if names, ok := monthNames[locale]; ok {
+		lower := strings.ToLower(value)
+
+		for idx, name := range names {
+			name = strings.ToLower(name)
+
+			if pos := strings.Index(lower, name); pos >= 0 {
+				value = value[:pos] + time.Month(idx+1).String() + value[pos+len(name):]
+				break
+			}
+		}
+	}
+
+	return time.Parse(layout, value)
+}
@@ -0,0 +1,62 @@
+package localdate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		layout  string
+		locale  string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			layout: "2 January 2006",
+			value:  "3 March 2024",
+			want:   time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			layout: "2. January 2006",
+			locale: "de",
+			value:  "3. März 2024",
+			want:   time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			layout: "2 January 2006",
+			locale: "fr",
+			value:  "17 DÉCEMBRE 2023",
+			want:   time.Date(2023, time.December, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			layout: "2 January 2006",
+			locale: "it",
+			value:  "1 luglio 2022",
+			want:   time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			layout: "2 January 2006",
+			locale: "nl",
+			value:  "9 mei 2021",
+			want:   time.Date(2021, time.May, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			layout:  "2 January 2006",
+			locale:  "de",
+			value:   "3. Foo 2024",
+			wantErr: true,
+		},
+	} {
+		got, err := Parse(tc.layout, tc.locale, tc.value)
+
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Parse(%q, %q, %q) returned error %v, want error %t", tc.layout, tc.locale, tc.value, err, tc.wantErr)
+		}
+
+		if err == nil && !got.Equal(tc.want) {
+			t.Errorf("Parse(%q, %q, %q) returned %v, want %v", tc.layout, tc.locale, tc.value, got, tc.want)
+		}
+	}
+}
@@ -112,6 +112,7 @@ type ObjectResolverClient interface {
 	CorrespondentClient
 	DocumentTypeClient
 	StoragePathClient
+	CustomFieldClient
 }
 
 type ObjectResolvers struct {
@@ -121,6 +122,7 @@ type ObjectResolvers struct {
 	Correspondent *CorrespondentResolver
 	DocumentType  *DocumentTypeResolver
 	StoragePath   *StoragePathResolver
+	CustomField   *CustomFieldResolver
 }
 
 func NewObjectResolvers(ctx context.Context, cl ObjectResolverClient, defaultPerm NamedObjectPermissions) (*ObjectResolvers, error) {
@@ -165,6 +167,9 @@ func NewObjectResolvers(ctx context.Context, cl ObjectResolverClient, defaultPer
 			PermissionOptions: permOpts,
 			Client:            cl,
 		}),
+		CustomField: NewCustomFieldResolver(CustomFieldResolverOptions{
+			Client: cl,
+		}),
 	}, nil
 }
 
@@ -176,5 +181,38 @@ func NewMemObjectResolvers() *ObjectResolvers {
 		Correspondent: NewMemCorrespondentResolver(),
 		DocumentType:  NewMemDocumentTypeResolver(),
 		StoragePath:   NewMemStoragePathResolver(),
+		CustomField:   NewMemCustomFieldResolver(),
+	}
+}
+
+// WithoutCreate returns resolvers which don't create missing objects (see
+// [Resolver.WithoutCreate]).
+func (r *ObjectResolvers) WithoutCreate() *ObjectResolvers {
+	return &ObjectResolvers{
+		User:          r.User.WithoutCreate(),
+		Group:         r.Group.WithoutCreate(),
+		Tag:           r.Tag.WithoutCreate(),
+		Correspondent: r.Correspondent.WithoutCreate(),
+		DocumentType:  r.DocumentType.WithoutCreate(),
+		StoragePath:   r.StoragePath.WithoutCreate(),
+		CustomField:   r.CustomField.WithoutCreate(),
+	}
+}
+
+// Planned returns the names of objects which would have been created by
+// resolvers in no-create mode, keyed by object kind.
+func (r *ObjectResolvers) Planned() map[string][]string {
+	result := map[string][]string{}
+
+	for kind, names := range map[string][]string{
+		"tag":           r.Tag.Planned(),
+		"correspondent": r.Correspondent.Planned(),
+		"document_type": r.DocumentType.Planned(),
+	} {
+		if len(names) > 0 {
+			result[kind] = names
+		}
 	}
+
+	return result
 }
@@ -92,6 +92,38 @@ func (c *fakeObjectResolverClient) CreateStoragePath(context.Context, *plclient.
 	return nil, nil, c.err
 }
 
+func (c *fakeObjectResolverClient) ListCustomFields(context.Context, plclient.ListCustomFieldsOptions) ([]plclient.CustomField, *plclient.Response, error) {
+	return []plclient.CustomField{}, nil, nil
+}
+
+func (c *fakeObjectResolverClient) GetUser(context.Context, int64) (*plclient.User, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
+func (c *fakeObjectResolverClient) GetGroup(context.Context, int64) (*plclient.Group, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
+func (c *fakeObjectResolverClient) GetTag(context.Context, int64) (*plclient.Tag, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
+func (c *fakeObjectResolverClient) GetCorrespondent(context.Context, int64) (*plclient.Correspondent, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
+func (c *fakeObjectResolverClient) GetDocumentType(context.Context, int64) (*plclient.DocumentType, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
+func (c *fakeObjectResolverClient) GetStoragePath(context.Context, int64) (*plclient.StoragePath, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
+func (c *fakeObjectResolverClient) GetCustomField(context.Context, int64) (*plclient.CustomField, *plclient.Response, error) {
+	return nil, nil, c.err
+}
+
 func TestObjectResolvers(t *testing.T) {
 	errTest := errors.New("test error")
 
@@ -167,6 +199,10 @@ func TestObjectResolvers(t *testing.T) {
 				if _, err := got.StoragePath.GetOrCreateByName(ctx, "xyz"); !errors.Is(err, ErrCreateUnsupported) {
 					t.Errorf("Creating storage path didn't fail with %v: %v", ErrCreateUnsupported, err)
 				}
+
+				if _, err := got.CustomField.GetOrCreateByName(ctx, "xyz"); !errors.Is(err, ErrCreateUnsupported) {
+					t.Errorf("Creating custom field didn't fail with %v: %v", ErrCreateUnsupported, err)
+				}
 			}
 		})
 	}
@@ -9,6 +9,7 @@ import (
 type CorrespondentClient interface {
 	ListCorrespondents(context.Context, plclient.ListCorrespondentsOptions) ([]plclient.Correspondent, *plclient.Response, error)
 	CreateCorrespondent(context.Context, *plclient.CorrespondentFields) (*plclient.Correspondent, *plclient.Response, error)
+	GetCorrespondent(context.Context, int64) (*plclient.Correspondent, *plclient.Response, error)
 }
 
 type correspondentProvider struct {
@@ -40,6 +41,15 @@ func (p *correspondentProvider) listByName(ctx context.Context, name string) ([]
 	return items, err
 }
 
+func (p *correspondentProvider) getByID(ctx context.Context, id int64) (plclient.Correspondent, error) {
+	item, _, err := p.Client.GetCorrespondent(ctx, id)
+	if err != nil {
+		return plclient.Correspondent{}, err
+	}
+
+	return *item, nil
+}
+
 type CorrespondentResolver = Resolver[plclient.Correspondent]
 
 type CorrespondentResolverOptions struct {
@@ -48,15 +58,17 @@ type CorrespondentResolverOptions struct {
 	Client CorrespondentClient
 }
 
+func newCorrespondent(id int64, name string) plclient.Correspondent {
+	return plclient.Correspondent{
+		ID:   id,
+		Name: name,
+	}
+}
+
 func NewCorrespondentResolver(opts CorrespondentResolverOptions) *CorrespondentResolver {
-	return newResolver[plclient.Correspondent](&correspondentProvider{opts})
+	return newResolver[plclient.Correspondent](&correspondentProvider{opts}, newCorrespondent)
 }
 
 func NewMemCorrespondentResolver() *CorrespondentResolver {
-	return newMemResolver(func(id int64, name string) plclient.Correspondent {
-		return plclient.Correspondent{
-			ID:   id,
-			Name: name,
-		}
-	})
+	return newMemResolver(newCorrespondent)
 }
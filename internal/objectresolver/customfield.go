@@ -0,0 +1,61 @@
+package objectresolver
+
+import (
+	"context"
+
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+)
+
+type CustomFieldClient interface {
+	ListCustomFields(context.Context, plclient.ListCustomFieldsOptions) ([]plclient.CustomField, *plclient.Response, error)
+	GetCustomField(context.Context, int64) (*plclient.CustomField, *plclient.Response, error)
+}
+
+// customFieldProvider doesn't create custom fields as their data type can't be
+// derived from the name.
+type customFieldProvider struct {
+	CustomFieldResolverOptions
+}
+
+func (customFieldProvider) kind() string {
+	return "custom field"
+}
+
+func (p *customFieldProvider) listByName(ctx context.Context, name string) ([]plclient.CustomField, error) {
+	opts := plclient.ListCustomFieldsOptions{}
+	opts.Name.EqualsIgnoringCase = &name
+
+	items, _, err := p.Client.ListCustomFields(ctx, opts)
+
+	return items, err
+}
+
+func (p *customFieldProvider) getByID(ctx context.Context, id int64) (plclient.CustomField, error) {
+	item, _, err := p.Client.GetCustomField(ctx, id)
+	if err != nil {
+		return plclient.CustomField{}, err
+	}
+
+	return *item, nil
+}
+
+type CustomFieldResolver = Resolver[plclient.CustomField]
+
+type CustomFieldResolverOptions struct {
+	Client CustomFieldClient
+}
+
+func newCustomField(id int64, name string) plclient.CustomField {
+	return plclient.CustomField{
+		ID:   id,
+		Name: name,
+	}
+}
+
+func NewCustomFieldResolver(opts CustomFieldResolverOptions) *CustomFieldResolver {
+	return newResolver[plclient.CustomField](&customFieldProvider{opts}, newCustomField)
+}
+
+func NewMemCustomFieldResolver() *CustomFieldResolver {
+	return newMemResolver(newCustomField)
+}
@@ -0,0 +1,9 @@
+package objectresolver
+
+import (
+	"testing"
+)
+
+func TestMemCustomFieldResolver(t *testing.T) {
+	validateMemResolver(t, NewMemCustomFieldResolver())
+}
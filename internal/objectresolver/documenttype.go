@@ -9,6 +9,7 @@ import (
 type DocumentTypeClient interface {
 	ListDocumentTypes(context.Context, plclient.ListDocumentTypesOptions) ([]plclient.DocumentType, *plclient.Response, error)
 	CreateDocumentType(context.Context, *plclient.DocumentTypeFields) (*plclient.DocumentType, *plclient.Response, error)
+	GetDocumentType(context.Context, int64) (*plclient.DocumentType, *plclient.Response, error)
 }
 
 type documentTypeProvider struct {
@@ -40,6 +41,15 @@ func (p *documentTypeProvider) listByName(ctx context.Context, name string) ([]p
 	return items, err
 }
 
+func (p *documentTypeProvider) getByID(ctx context.Context, id int64) (plclient.DocumentType, error) {
+	item, _, err := p.Client.GetDocumentType(ctx, id)
+	if err != nil {
+		return plclient.DocumentType{}, err
+	}
+
+	return *item, nil
+}
+
 type DocumentTypeResolver = Resolver[plclient.DocumentType]
 
 type DocumentTypeResolverOptions struct {
@@ -48,15 +58,17 @@ type DocumentTypeResolverOptions struct {
 	Client DocumentTypeClient
 }
 
+func newDocumentType(id int64, name string) plclient.DocumentType {
+	return plclient.DocumentType{
+		ID:   id,
+		Name: name,
+	}
+}
+
 func NewDocumentTypeResolver(opts DocumentTypeResolverOptions) *DocumentTypeResolver {
-	return newResolver[plclient.DocumentType](&documentTypeProvider{opts})
+	return newResolver[plclient.DocumentType](&documentTypeProvider{opts}, newDocumentType)
 }
 
 func NewMemDocumentTypeResolver() *DocumentTypeResolver {
-	return newMemResolver(func(id int64, name string) plclient.DocumentType {
-		return plclient.DocumentType{
-			ID:   id,
-			Name: name,
-		}
-	})
+	return newMemResolver(newDocumentType)
 }
@@ -8,6 +8,7 @@ import (
 
 type GroupClient interface {
 	ListGroups(context.Context, plclient.ListGroupsOptions) ([]plclient.Group, *plclient.Response, error)
+	GetGroup(context.Context, int64) (*plclient.Group, *plclient.Response, error)
 }
 
 type groupProvider struct {
@@ -27,8 +28,13 @@ func (p groupProvider) listByName(ctx context.Context, name string) ([]plclient.
 	return items, err
 }
 
-func (p groupProvider) create(ctx context.Context, name string) error {
-	return ErrCreateUnsupported
+func (p groupProvider) getByID(ctx context.Context, id int64) (plclient.Group, error) {
+	item, _, err := p.Client.GetGroup(ctx, id)
+	if err != nil {
+		return plclient.Group{}, err
+	}
+
+	return *item, nil
 }
 
 type GroupResolver = Resolver[plclient.Group]
@@ -37,15 +43,17 @@ type GroupResolverOptions struct {
 	Client GroupClient
 }
 
+func newGroup(id int64, name string) plclient.Group {
+	return plclient.Group{
+		ID:   id,
+		Name: name,
+	}
+}
+
 func NewGroupResolver(opts GroupResolverOptions) *GroupResolver {
-	return newResolver[plclient.Group](&groupProvider{opts})
+	return newResolver[plclient.Group](&groupProvider{opts}, newGroup)
 }
 
 func NewMemGroupResolver() *GroupResolver {
-	return newMemResolver(func(id int64, name string) plclient.Group {
-		return plclient.Group{
-			ID:   id,
-			Name: name,
-		}
-	})
+	return newMemResolver(newGroup)
 }
@@ -20,6 +20,7 @@ type memProvider[T any] struct {
 }
 
 var _ provider[struct{}] = (*memProvider[struct{}])(nil)
+var _ creator = (*memProvider[struct{}])(nil)
 
 func (p *memProvider[T]) kind() string {
 	return reflect.TypeOf(p).Elem().Name()
@@ -56,6 +57,23 @@ func (p *memProvider[T]) listByName(_ context.Context, name string) ([]T, error)
 	return nil, nil
 }
 
+func (p *memProvider[T]) getByID(_ context.Context, id int64) (T, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, v := range p.objects {
+		if rv := reflect.ValueOf(v); rv.Kind() != reflect.Struct {
+			break
+		} else if f := rv.FieldByName("ID"); f.IsValid() && f.Int() == id {
+			return v, nil
+		}
+	}
+
+	var zero T
+
+	return zero, fmt.Errorf("%w: ID %d", ErrNotFound, id)
+}
+
 func newMemProvider[T any](create func(id int64, name string) T) *memProvider[T] {
 	var id atomic.Int64
 
@@ -72,5 +90,5 @@ func newMemProvider[T any](create func(id int64, name string) T) *memProvider[T]
 // create is an optional function to create a new value for a key. By
 // default values are set to their zero state.
 func newMemResolver[T any](create func(id int64, name string) T) *Resolver[T] {
-	return newResolver[T](newMemProvider[T](create))
+	return newResolver[T](newMemProvider[T](create), create)
 }
@@ -3,6 +3,7 @@ package objectresolver
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -18,13 +19,26 @@ func validateMemResolver[T any](t *testing.T, r *Resolver[T]) {
 			t.Errorf("GetByName(%q) returned unexpected error (%#v): %v", name, got, err)
 		}
 
-		if _, err := r.GetOrCreateByName(ctx, name); err != nil {
+		obj, err := r.GetOrCreateByName(ctx, name)
+		if err != nil {
 			t.Errorf("GetOrCreateByName(%q) should have created an object, failed instead: %v", name, err)
 		}
 
 		if _, err := r.GetByName(ctx, name); err != nil {
 			t.Errorf("GetByName(%q) failed: %v", name, err)
 		}
+
+		id := reflect.ValueOf(obj).FieldByName("ID").Int()
+
+		if got, err := r.GetByID(ctx, id); err != nil {
+			t.Errorf("GetByID(%d) failed: %v", id, err)
+		} else if diff := cmp.Diff(obj, got); diff != "" {
+			t.Errorf("GetByID(%d) diff (-want +got):\n%s", id, diff)
+		}
+	}
+
+	if got, err := r.GetByID(ctx, -1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID() returned unexpected error (%#v): %v", got, err)
 	}
 
 }
@@ -4,30 +4,168 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"golang.org/x/sync/singleflight"
 )
 
+// idCacheTTL is how long objects looked up by their ID are reused. Renamed
+// objects are picked up once it has elapsed.
+const idCacheTTL = 10 * time.Minute
+
 type provider[T any] interface {
 	kind() string
-	create(ctx context.Context, name string) error
 	listByName(ctx context.Context, name string) ([]T, error)
+	getByID(ctx context.Context, id int64) (T, error)
+}
+
+// creator is implemented by providers able to create objects. Resolvers
+// report [ErrCreateUnsupported] for other providers.
+type creator interface {
+	create(ctx context.Context, name string) error
+}
+
+// plannedObjects records objects which would have been created.
+type plannedObjects[T any] struct {
+	mu          sync.Mutex
+	names       []string
+	byName      map[string]T
+	placeholder func(id int64, name string) T
+}
+
+func (p *plannedObjects[T]) get(name string) (T, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	obj, ok := p.byName[name]
+
+	return obj, ok
+}
+
+func (p *plannedObjects[T]) add(name string) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if obj, ok := p.byName[name]; ok {
+		return obj
+	}
+
+	// Placeholders use negative IDs to never collide with real objects.
+	obj := p.placeholder(-int64(1+len(p.names)), name)
+
+	p.names = append(p.names, name)
+	p.byName[name] = obj
+
+	return obj
+}
+
+func (p *plannedObjects[T]) list() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.names)
+}
+
+type idCacheEntry[T any] struct {
+	obj     T
+	expires time.Time
+}
+
+// idCache keeps objects retrieved by their ID.
+type idCache[T any] struct {
+	mu      sync.Mutex
+	clock   clockwork.Clock
+	entries map[int64]idCacheEntry[T]
+}
+
+func newIDCache[T any]() *idCache[T] {
+	return &idCache[T]{
+		clock:   clockwork.NewRealClock(),
+		entries: map[int64]idCacheEntry[T]{},
+	}
+}
+
+func (c *idCache[T]) get(id int64) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok || c.clock.Now().After(entry.expires) {
+		var zero T
+
+		return zero, false
+	}
+
+	return entry.obj, true
+}
+
+func (c *idCache[T]) set(id int64, obj T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[id] = idCacheEntry[T]{
+		obj:     obj,
+		expires: c.clock.Now().Add(idCacheTTL),
+	}
 }
 
 type Resolver[T any] struct {
 	kind string
 	zero T
 	sf   singleflight.Group
+	sfID singleflight.Group
 	p    provider[T]
+
+	// Returns a new object with the given ID and name. Used for placeholders
+	// in no-create mode.
+	newObject func(id int64, name string) T
+
+	// Objects retrieved by ID. Shared with resolvers in no-create mode.
+	byID *idCache[T]
+
+	// Objects which would have been created. Only set in no-create mode.
+	planned *plannedObjects[T]
+}
+
+func newResolver[T any](p provider[T], newObject func(id int64, name string) T) *Resolver[T] {
+	return &Resolver[T]{
+		kind:      p.kind(),
+		p:         p,
+		newObject: newObject,
+		byID:      newIDCache[T](),
+	}
 }
 
-func newResolver[T any](p provider[T]) *Resolver[T] {
+// WithoutCreate returns a resolver for the same objects which doesn't create
+// missing objects. A placeholder object with a negative ID is returned instead
+// and the name is recorded (see [Resolver.Planned]).
+func (r *Resolver[T]) WithoutCreate() *Resolver[T] {
 	return &Resolver[T]{
-		kind: p.kind(),
-		p:    p,
+		kind:      r.kind,
+		p:         r.p,
+		newObject: r.newObject,
+		byID:      r.byID,
+		planned: &plannedObjects[T]{
+			byName:      map[string]T{},
+			placeholder: r.newObject,
+		},
 	}
 }
 
+// Planned returns the names of objects which would have been created by
+// a resolver in no-create mode.
+func (r *Resolver[T]) Planned() []string {
+	if r.planned == nil {
+		return nil
+	}
+
+	return r.planned.list()
+}
+
 func (r *Resolver[T]) once(key string, fn func() (T, error)) (T, error) {
 	result, err, _ := r.sf.Do(key, func() (any, error) {
 		return fn()
@@ -42,6 +180,12 @@ func (r *Resolver[T]) once(key string, fn func() (T, error)) (T, error) {
 
 func (r *Resolver[T]) getFirst(name string, objs []T) (T, error) {
 	if len(objs) == 0 {
+		if r.planned != nil {
+			if obj, ok := r.planned.get(name); ok {
+				return obj, nil
+			}
+		}
+
 		return r.zero, fmt.Errorf("%w: %s %q", ErrNotFound, r.kind, name)
 	}
 
@@ -67,12 +211,49 @@ func (r *Resolver[T]) GetByName(ctx context.Context, name string) (T, error) {
 	})
 }
 
+// GetByID returns the object with the given ID. Objects are cached for
+// a while to avoid repeated lookups, e.g. for the tags of every document.
+func (r *Resolver[T]) GetByID(ctx context.Context, id int64) (T, error) {
+	if obj, ok := r.byID.get(id); ok {
+		return obj, nil
+	}
+
+	result, err, _ := r.sfID.Do(strconv.FormatInt(id, 10), func() (any, error) {
+		obj, err := r.p.getByID(ctx, id)
+		if err == nil {
+			r.byID.set(id, obj)
+		}
+
+		return obj, err
+	})
+
+	if err != nil {
+		return r.zero, fmt.Errorf("getting %s %d: %w", r.kind, id, err)
+	}
+
+	return result.(T), nil
+}
+
+// plan records an object which would have been created.
+func (r *Resolver[T]) plan(name string) T {
+	return r.planned.add(name)
+}
+
 func (r *Resolver[T]) GetOrCreateByName(ctx context.Context, name string) (T, error) {
 	return r.once(name, func() (T, error) {
 		obj, err := r.getByName(ctx, name)
 
 		if errors.Is(err, ErrNotFound) {
-			if err := r.p.create(ctx, name); err != nil {
+			c, ok := r.p.(creator)
+			if !ok {
+				return r.zero, fmt.Errorf("creating %s %q: %w", r.kind, name, ErrCreateUnsupported)
+			}
+
+			if r.planned != nil {
+				return r.plan(name), nil
+			}
+
+			if err := c.create(ctx, name); err != nil {
 				return r.zero, fmt.Errorf("creating %s %q: %w", r.kind, name, err)
 			}
 
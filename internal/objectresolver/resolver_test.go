@@ -5,6 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/jonboulle/clockwork"
 )
 
 func TestResolver(t *testing.T) {
@@ -14,7 +19,7 @@ func TestResolver(t *testing.T) {
 		return fmt.Sprintf("value %s", name)
 	})
 
-	r := newResolver[string](p)
+	r := newResolver[string](p, nil)
 
 	if _, err := r.GetByName(ctx, "1"); !errors.Is(err, ErrNotFound) {
 		t.Errorf("Missing item not reported as such: %v", err)
@@ -34,3 +39,110 @@ func TestResolver(t *testing.T) {
 		t.Errorf("GetOrCreateByName() returned %q, want %q", got, want)
 	}
 }
+
+func TestResolverWithoutCreate(t *testing.T) {
+	ctx := context.Background()
+
+	p := newMemProvider(newTag)
+	p.set("existing", plclient.Tag{ID: 123, Name: "existing"})
+
+	r := newResolver[plclient.Tag](p, newTag).WithoutCreate()
+
+	if got, err := r.GetOrCreateByName(ctx, "existing"); err != nil {
+		t.Errorf("GetOrCreateByName() failed: %v", err)
+	} else if diff := cmp.Diff(plclient.Tag{ID: 123, Name: "existing"}, got); diff != "" {
+		t.Errorf("GetOrCreateByName() diff (-want +got):\n%s", diff)
+	}
+
+	for _, name := range []string{"first", "second", "first"} {
+		if got, err := r.GetOrCreateByName(ctx, name); err != nil {
+			t.Errorf("GetOrCreateByName(%q) failed: %v", name, err)
+		} else if got.ID >= 0 || got.Name != name {
+			t.Errorf("GetOrCreateByName(%q) returned %+v, want placeholder", name, got)
+		}
+	}
+
+	if got, err := r.GetByName(ctx, "second"); err != nil {
+		t.Errorf("GetByName() failed: %v", err)
+	} else if got.ID >= 0 {
+		t.Errorf("GetByName() returned %+v, want placeholder", got)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second"}, r.Planned()); diff != "" {
+		t.Errorf("Planned() diff (-want +got):\n%s", diff)
+	}
+
+	if objs, err := p.listByName(ctx, "first"); err != nil {
+		t.Errorf("listByName() failed: %v", err)
+	} else if len(objs) != 0 {
+		t.Errorf("Object was created: %+v", objs)
+	}
+
+	if _, err := NewMemStoragePathResolver().WithoutCreate().GetOrCreateByName(ctx, "path"); err != nil {
+		t.Errorf("Creating storage path in memory failed: %v", err)
+	}
+
+	sp := NewStoragePathResolver(StoragePathResolverOptions{
+		Client: &fakeObjectResolverClient{},
+	}).WithoutCreate()
+
+	if _, err := sp.GetOrCreateByName(ctx, "path"); !errors.Is(err, ErrCreateUnsupported) {
+		t.Errorf("GetOrCreateByName() didn't fail with %v: %v", ErrCreateUnsupported, err)
+	}
+}
+
+type countingProvider struct {
+	*memProvider[plclient.Tag]
+	getByIDCount int
+}
+
+func (p *countingProvider) getByID(ctx context.Context, id int64) (plclient.Tag, error) {
+	p.getByIDCount++
+
+	return p.memProvider.getByID(ctx, id)
+}
+
+func TestResolverGetByIDCache(t *testing.T) {
+	ctx := context.Background()
+
+	p := &countingProvider{memProvider: newMemProvider(newTag)}
+	p.set("first", plclient.Tag{ID: 1, Name: "first"})
+
+	r := newResolver[plclient.Tag](p, newTag)
+
+	clock := clockwork.NewFakeClock()
+	r.byID.clock = clock
+
+	for _, tc := range []struct {
+		advance   time.Duration
+		wantCount int
+	}{
+		{wantCount: 1},
+		{advance: time.Minute, wantCount: 1},
+		{advance: idCacheTTL, wantCount: 2},
+	} {
+		clock.Advance(tc.advance)
+
+		if got, err := r.GetByID(ctx, 1); err != nil {
+			t.Errorf("GetByID() failed: %v", err)
+		} else if got.Name != "first" {
+			t.Errorf("GetByID() returned %+v", got)
+		}
+
+		if _, err := r.WithoutCreate().GetByID(ctx, 1); err != nil {
+			t.Errorf("GetByID() without create failed: %v", err)
+		}
+
+		if p.getByIDCount != tc.wantCount {
+			t.Errorf("Provider called %d times, want %d", p.getByIDCount, tc.wantCount)
+		}
+	}
+
+	if _, err := r.GetByID(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID() returned unexpected error: %v", err)
+	}
+
+	if _, err := r.GetByID(ctx, 2); !errors.Is(err, ErrNotFound) || p.getByIDCount != 4 {
+		t.Errorf("Missing object was cached (%d calls): %v", p.getByIDCount, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 type StoragePathClient interface {
 	ListStoragePaths(context.Context, plclient.ListStoragePathsOptions) ([]plclient.StoragePath, *plclient.Response, error)
 	CreateStoragePath(context.Context, *plclient.StoragePathFields) (*plclient.StoragePath, *plclient.Response, error)
+	GetStoragePath(context.Context, int64) (*plclient.StoragePath, *plclient.Response, error)
 }
 
 type storagePathProvider struct {
@@ -19,10 +20,6 @@ func (storagePathProvider) kind() string {
 	return "storagePath"
 }
 
-func (p *storagePathProvider) create(ctx context.Context, name string) error {
-	return ErrCreateUnsupported
-}
-
 func (p *storagePathProvider) listByName(ctx context.Context, name string) ([]plclient.StoragePath, error) {
 	opts := plclient.ListStoragePathsOptions{}
 	opts.Name.EqualsIgnoringCase = &name
@@ -32,6 +29,15 @@ func (p *storagePathProvider) listByName(ctx context.Context, name string) ([]pl
 	return items, err
 }
 
+func (p *storagePathProvider) getByID(ctx context.Context, id int64) (plclient.StoragePath, error) {
+	item, _, err := p.Client.GetStoragePath(ctx, id)
+	if err != nil {
+		return plclient.StoragePath{}, err
+	}
+
+	return *item, nil
+}
+
 type StoragePathResolver = Resolver[plclient.StoragePath]
 
 type StoragePathResolverOptions struct {
@@ -40,15 +46,17 @@ type StoragePathResolverOptions struct {
 	Client StoragePathClient
 }
 
+func newStoragePath(id int64, name string) plclient.StoragePath {
+	return plclient.StoragePath{
+		ID:   id,
+		Name: name,
+	}
+}
+
 func NewStoragePathResolver(opts StoragePathResolverOptions) *StoragePathResolver {
-	return newResolver[plclient.StoragePath](&storagePathProvider{opts})
+	return newResolver[plclient.StoragePath](&storagePathProvider{opts}, newStoragePath)
 }
 
 func NewMemStoragePathResolver() *StoragePathResolver {
-	return newMemResolver(func(id int64, name string) plclient.StoragePath {
-		return plclient.StoragePath{
-			ID:   id,
-			Name: name,
-		}
-	})
+	return newMemResolver(newStoragePath)
 }
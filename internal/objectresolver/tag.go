@@ -9,6 +9,7 @@ import (
 type TagClient interface {
 	ListTags(context.Context, plclient.ListTagsOptions) ([]plclient.Tag, *plclient.Response, error)
 	CreateTag(context.Context, *plclient.TagFields) (*plclient.Tag, *plclient.Response, error)
+	GetTag(context.Context, int64) (*plclient.Tag, *plclient.Response, error)
 }
 
 type tagProvider struct {
@@ -40,6 +41,15 @@ func (p *tagProvider) listByName(ctx context.Context, name string) ([]plclient.T
 	return items, err
 }
 
+func (p *tagProvider) getByID(ctx context.Context, id int64) (plclient.Tag, error) {
+	item, _, err := p.Client.GetTag(ctx, id)
+	if err != nil {
+		return plclient.Tag{}, err
+	}
+
+	return *item, nil
+}
+
 type TagResolver = Resolver[plclient.Tag]
 
 type TagResolverOptions struct {
@@ -48,15 +58,17 @@ type TagResolverOptions struct {
 	Client TagClient
 }
 
+func newTag(id int64, name string) plclient.Tag {
+	return plclient.Tag{
+		ID:   id,
+		Name: name,
+	}
+}
+
 func NewTagResolver(opts TagResolverOptions) *TagResolver {
-	return newResolver[plclient.Tag](&tagProvider{opts})
+	return newResolver[plclient.Tag](&tagProvider{opts}, newTag)
 }
 
 func NewMemTagResolver() *TagResolver {
-	return newMemResolver(func(id int64, name string) plclient.Tag {
-		return plclient.Tag{
-			ID:   id,
-			Name: name,
-		}
-	})
+	return newMemResolver(newTag)
 }
@@ -29,6 +29,14 @@ func (c *fakeTagClient) CreateTag(context.Context, *plclient.TagFields) (*plclie
 	return ref.Ref(*c.tag), nil, nil
 }
 
+func (c *fakeTagClient) GetTag(_ context.Context, id int64) (*plclient.Tag, *plclient.Response, error) {
+	if c.tag == nil || c.tag.ID != id {
+		return nil, nil, ErrNotFound
+	}
+
+	return ref.Ref(*c.tag), nil, nil
+}
+
 func TestTagResolver(t *testing.T) {
 	ctx := context.Background()
 
@@ -37,6 +45,10 @@ func TestTagResolver(t *testing.T) {
 		Client: cl,
 	})
 
+	if got, err := r.GetByID(ctx, 123); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByID() returned unexpected error (%#v): %v", got, err)
+	}
+
 	for _, name := range []string{"", "missing"} {
 		if got, err := r.GetByName(ctx, name); !errors.Is(err, ErrNotFound) {
 			t.Errorf("GetByName(%q) returned unexpected error (%#v): %v", name, got, err)
@@ -57,6 +69,12 @@ func TestTagResolver(t *testing.T) {
 	} else if !(got.ID == 123 && got.Name == "test") {
 		t.Errorf("GetByName() returned unexpected %#v", got)
 	}
+
+	if got, err := r.GetByID(ctx, 123); err != nil {
+		t.Errorf("GetByID() failed: %v", err)
+	} else if got.Name != "test" {
+		t.Errorf("GetByID() returned unexpected %#v", got)
+	}
 }
 
 func TestMemTagResolver(t *testing.T) {
@@ -8,6 +8,7 @@ import (
 
 type UserClient interface {
 	ListUsers(context.Context, plclient.ListUsersOptions) ([]plclient.User, *plclient.Response, error)
+	GetUser(context.Context, int64) (*plclient.User, *plclient.Response, error)
 }
 
 type userProvider struct {
@@ -27,8 +28,13 @@ func (p userProvider) listByName(ctx context.Context, name string) ([]plclient.U
 	return items, err
 }
 
-func (p userProvider) create(ctx context.Context, name string) error {
-	return ErrCreateUnsupported
+func (p userProvider) getByID(ctx context.Context, id int64) (plclient.User, error) {
+	item, _, err := p.Client.GetUser(ctx, id)
+	if err != nil {
+		return plclient.User{}, err
+	}
+
+	return *item, nil
 }
 
 type UserResolver = Resolver[plclient.User]
@@ -37,15 +43,17 @@ type UserResolverOptions struct {
 	Client UserClient
 }
 
+func newUser(id int64, name string) plclient.User {
+	return plclient.User{
+		ID:       id,
+		Username: name,
+	}
+}
+
 func NewUserResolver(opts UserResolverOptions) *UserResolver {
-	return newResolver[plclient.User](&userProvider{opts})
+	return newResolver[plclient.User](&userProvider{opts}, newUser)
 }
 
 func NewMemUserResolver() *UserResolver {
-	return newMemResolver(func(id int64, name string) plclient.User {
-		return plclient.User{
-			ID:       id,
-			Username: name,
-		}
-	})
+	return newMemResolver(newUser)
 }
@@ -0,0 +1,54 @@
+// Package pdftext extracts the text of PDF documents using the pdftotext
+// program from Poppler, the PDF library also used by dossier.
+package pdftext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pages returns the text of the pages from first to last (inclusive), one
+// element per page. Page numbers start at 1. Zero selects the first or last
+// page of the document respectively.
+func Pages(ctx context.Context, path string, first, last int) ([]string, error) {
+	args := []string{"-layout", "-enc", "UTF-8"}
+
+	if first > 0 {
+		args = append(args, "-f", strconv.Itoa(first))
+	}
+
+	if last > 0 {
+		args = append(args, "-l", strconv.Itoa(last))
+	}
+
+	args = append(args, path, "-")
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "pdftotext", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftotext: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return split(stdout.Bytes()), nil
+}
+
+// split divides the output of pdftotext into pages. Every page is terminated
+// by a form feed.
+func split(data []byte) []string {
+	text := strings.ToValidUTF8(string(data), string(utf8.RuneError))
+
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\f"), "\f")
+}
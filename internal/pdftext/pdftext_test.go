@@ -0,0 +1,52 @@
+package pdftext
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSplit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "empty"},
+		{name: "one page", data: "first\n\f", want: []string{"first\n"}},
+		{name: "empty page", data: "first\f\fthird\f", want: []string{"first", "", "third"}},
+		{name: "unterminated", data: "first\fsecond", want: []string{"first", "second"}},
+		{name: "invalid UTF-8", data: "a\xffb\f", want: []string{"a�b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, split([]byte(tc.data)), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("split() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPages(t *testing.T) {
+	if _, err := exec.LookPath("pdftotext"); err != nil {
+		t.Skipf("pdftotext not available: %v", err)
+	}
+
+	path := filepath.Join("..", "..", "example", "myminer", "invoice", "testdata", "invoice.pdf")
+
+	got, err := Pages(context.Background(), path, 1, 1)
+	if err != nil {
+		t.Fatalf("Pages() failed: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Errorf("Pages() returned %d pages, want 1", len(got))
+	}
+
+	if _, err := Pages(context.Background(), filepath.Join(t.TempDir(), "missing.pdf"), 0, 0); err == nil {
+		t.Errorf("Pages() succeeded for missing file")
+	}
+}
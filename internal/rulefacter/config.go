@@ -0,0 +1,94 @@
+package rulefacter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the top-level structure of a rule file.
+type fileConfig struct {
+	Rules []ruleConfig `yaml:"rules"`
+}
+
+type ruleConfig struct {
+	// Unique rule name used in log messages.
+	Name string `yaml:"name"`
+
+	// Priority of reported facts (see [paperminer.Facts.Priority]).
+	Priority int `yaml:"priority"`
+
+	Match matchConfig `yaml:"match"`
+	Facts factsConfig `yaml:"facts"`
+}
+
+// matchConfig describes the conditions for a rule to match. All given
+// conditions must be fulfilled.
+type matchConfig struct {
+	// Regular expression matched against the original filename.
+	Filename string `yaml:"filename"`
+
+	// Regular expression matched against the text content of the document.
+	Text string `yaml:"text"`
+
+	// Dossier sketch in textproto format evaluated on the document file.
+	// Mutually exclusive with Text.
+	Sketch string `yaml:"sketch"`
+
+	// Sketch nodes which must be valid.
+	RequiredNodes []string `yaml:"required_nodes"`
+
+	// Pages evaluated by the sketch: "first" (default), "last" or "all".
+	Pages string `yaml:"pages"`
+}
+
+type factsConfig struct {
+	// Go template producing the title. The "group" function returns the text
+	// of a named group, i.e. {{ group "name" }} for text rules and
+	// {{ group "node" "name" }} for sketch rules.
+	Title string `yaml:"title"`
+
+	Created *createdConfig `yaml:"created"`
+
+	Correspondent string   `yaml:"correspondent"`
+	DocumentType  string   `yaml:"document_type"`
+	StoragePath   string   `yaml:"storage_path"`
+	Tags          []string `yaml:"tags"`
+}
+
+type createdConfig struct {
+	// Sketch node containing the date. Only used by sketch rules.
+	Node string `yaml:"node"`
+
+	// Named group in the regular expression containing the date.
+	Group string `yaml:"group"`
+
+	// Layout as accepted by [time.Parse].
+	Layout string `yaml:"layout"`
+
+	// Language of month names, e.g. "de".
+	Locale string `yaml:"locale"`
+}
+
+// parseConfig decodes a rule file in YAML format. Unknown fields are
+// rejected.
+func parseConfig(r io.Reader) (*fileConfig, error) {
+	var cfg fileConfig
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", os.ErrInvalid, err)
+	}
+
+	return &cfg, nil
+}
+
+func parseConfigBytes(data []byte) (*fileConfig, error) {
+	return parseConfig(bytes.NewReader(data))
+}
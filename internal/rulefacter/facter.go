@@ -0,0 +1,152 @@
+// Package rulefacter implements a built-in facter using rules loaded from
+// a file. Rules pair match conditions with the facts to set, allowing simple
+// rules to be added without recompiling.
+package rulefacter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/staticplug"
+	"go.uber.org/zap"
+)
+
+// Name of the built-in facter.
+const Name = "rules"
+
+type Facter struct {
+	// Rules in file order. The first matching rule wins.
+	content  []*rule
+	document []*rule
+}
+
+var _ staticplug.Plugin = (*Facter)(nil)
+var _ paperminer.DocumentFacter = (*Facter)(nil)
+var _ paperminer.ContentFacter = (*Facter)(nil)
+
+// Parse validates and compiles rules in YAML format.
+func Parse(data []byte) (*Facter, error) {
+	cfg, err := parseConfigBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Facter{}
+	names := map[string]struct{}{}
+
+	for idx, rc := range cfg.Rules {
+		r, err := compileRule(rc)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%q): %w", idx+1, rc.Name, err)
+		}
+
+		if _, ok := names[r.name]; ok {
+			return nil, fmt.Errorf("%w: duplicate rule name %q", os.ErrInvalid, r.name)
+		}
+
+		names[r.name] = struct{}{}
+
+		if r.sketch != nil {
+			f.document = append(f.document, r)
+		} else {
+			f.content = append(f.content, r)
+		}
+	}
+
+	return f, nil
+}
+
+// Load reads rules from a file.
+func Load(path string) (*Facter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("rule file %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+func (f *Facter) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: Name,
+		New: func() (staticplug.Plugin, error) {
+			// Only implement the facter interfaces for which there are
+			// rules. Documents are not downloaded without document facters.
+			switch {
+			case len(f.document) == 0:
+				return contentFacter{f}, nil
+			case len(f.content) == 0:
+				return documentFacter{f}, nil
+			}
+
+			return f, nil
+		},
+	}
+}
+
+func (f *Facter) ContentFacts(ctx context.Context, opts paperminer.ContentFacterOptions) (*paperminer.Facts, error) {
+	for _, r := range f.content {
+		facts, err := r.contentFacts(opts.Info, opts.Content)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.name, err)
+		}
+
+		if facts != nil {
+			opts.Logger.Debug("Rule matched", zap.String("rule", r.name))
+			return facts, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (f *Facter) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	for _, r := range f.document {
+		facts, err := r.documentFacts(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.name, err)
+		}
+
+		if facts != nil {
+			opts.Logger.Debug("Rule matched", zap.String("rule", r.name))
+			return facts, nil
+		}
+	}
+
+	return nil, nil
+}
+
+type contentFacter struct {
+	f *Facter
+}
+
+var _ paperminer.ContentFacter = contentFacter{}
+
+func (c contentFacter) PluginInfo() staticplug.PluginInfo {
+	return c.f.PluginInfo()
+}
+
+func (c contentFacter) ContentFacts(ctx context.Context, opts paperminer.ContentFacterOptions) (*paperminer.Facts, error) {
+	return c.f.ContentFacts(ctx, opts)
+}
+
+type documentFacter struct {
+	f *Facter
+}
+
+var _ paperminer.DocumentFacter = documentFacter{}
+
+func (d documentFacter) PluginInfo() staticplug.PluginInfo {
+	return d.f.PluginInfo()
+}
+
+func (d documentFacter) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	return d.f.DocumentFacts(ctx, opts)
+}
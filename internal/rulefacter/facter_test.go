@@ -0,0 +1,294 @@
+package rulefacter
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"go.uber.org/zap/zaptest"
+)
+
+const testSketch = `
+nodes {
+  name: "label"
+  search_areas {
+    top_left { abs { left { cm: 1 } top { cm: 1 } } }
+    width { cm: 10 }
+    height { cm: 10 }
+  }
+  line_text { regex: "(?i)invoice" }
+}
+
+nodes {
+  name: "number"
+  search_areas {
+    top_left { rel { node: "label" feature: TOP_LEFT } }
+    width { cm: 5 }
+    height { cm: 1 }
+  }
+  line_text { regex: "(?P<value>\\d+)" }
+}
+`
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		input        string
+		wantErr      error
+		wantContent  []string
+		wantDocument []string
+	}{
+		{name: "empty"},
+		{
+			name: "content and sketch rules",
+			input: `
+rules:
+  - name: acme
+    match:
+      text: '(?i)acme\s+corp'
+    facts:
+      correspondent: ACME
+  - name: scan
+    match:
+      filename: '^scan_'
+    facts:
+      tags: [scanned]
+  - name: invoice
+    match:
+      sketch: |` + indent(testSketch, "        ") + `
+      required_nodes: [label]
+      pages: all
+    facts:
+      title: 'Invoice {{ group "number" "value" }}'
+      document_type: Invoice
+`,
+			wantContent:  []string{"acme", "scan"},
+			wantDocument: []string{"invoice"},
+		},
+		{
+			name:    "unknown field",
+			input:   "rules: [{name: x, unknown: 1}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "missing name",
+			input:   "rules: [{match: {text: x}, facts: {correspondent: y}}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "duplicate name",
+			input: `
+rules:
+  - {name: x, match: {text: a}, facts: {correspondent: y}}
+  - {name: x, match: {text: b}, facts: {correspondent: z}}
+`,
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "no condition",
+			input:   "rules: [{name: x, facts: {correspondent: y}}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "no facts",
+			input:   "rules: [{name: x, match: {text: a}}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "invalid regex",
+			input:   "rules: [{name: x, match: {text: '('}, facts: {correspondent: y}}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "text and sketch",
+			input:   "rules: [{name: x, match: {text: a, sketch: 'nodes {}'}, facts: {correspondent: y}}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "pages without sketch",
+			input:   "rules: [{name: x, match: {text: a, pages: all}, facts: {correspondent: y}}]",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "unknown group in title",
+			input:   `rules: [{name: x, match: {text: '(?P<num>\d+)'}, facts: {title: '{{ group "number" }}'}}]`,
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "created without layout",
+			input:   `rules: [{name: x, match: {text: '(?P<date>\S+)'}, facts: {created: {group: date}}}]`,
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unknown sketch node",
+			input: `
+rules:
+  - name: invoice
+    match:
+      sketch: |` + indent(testSketch, "        ") + `
+    facts:
+      title: '{{ group "missing" "value" }}'
+`,
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Parse([]byte(tc.input))
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err != nil {
+				return
+			}
+
+			names := func(rules []*rule) []string {
+				var result []string
+
+				for _, r := range rules {
+					result = append(result, r.name)
+				}
+
+				return result
+			}
+
+			if diff := cmp.Diff(tc.wantContent, names(f.content)); diff != "" {
+				t.Errorf("Content rules diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.wantDocument, names(f.document)); diff != "" {
+				t.Errorf("Document rules diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func indent(s, prefix string) string {
+	return strings.ReplaceAll("\n"+strings.TrimSpace(s), "\n", "\n"+prefix)
+}
+
+func TestContentFacts(t *testing.T) {
+	f, err := Parse([]byte(`
+rules:
+  - name: acme
+    priority: 5
+    match:
+      filename: '(?i)\.pdf$'
+      text: 'ACME Corp.*Invoice (?P<number>\d+) from (?P<date>\d+\. \S+ \d{4})'
+    facts:
+      title: 'ACME invoice {{ group "number" }}'
+      created: {group: date, layout: '2. January 2006', locale: de}
+      correspondent: ACME
+      tags: [invoice]
+  - name: fallback
+    match:
+      text: 'ACME'
+    facts:
+      correspondent: ACME
+`))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		filename string
+		content  string
+		want     *paperminer.Facts
+	}{
+		{
+			name:     "no match",
+			filename: "file.pdf",
+			content:  "Hello World",
+		},
+		{
+			name:     "first rule",
+			filename: "file.pdf",
+			content:  "ACME Corp, Invoice 1234 from 3. März 2024",
+			want: &paperminer.Facts{
+				Priority:      5,
+				Title:         ref.Ref("ACME invoice 1234"),
+				Created:       ref.Ref(time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)),
+				Correspondent: ref.Ref("ACME"),
+				SetTags:       []string{"invoice"},
+			},
+		},
+		{
+			name:     "filename mismatch",
+			filename: "file.txt",
+			content:  "ACME Corp, Invoice 1234 from 3. März 2024",
+			want: &paperminer.Facts{
+				Correspondent: ref.Ref("ACME"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := f.ContentFacts(context.Background(), paperminer.ContentFacterOptions{
+				Logger:  zaptest.NewLogger(t),
+				Info:    &paperminer.DocumentInfo{OriginalFileName: tc.filename},
+				Content: tc.content,
+			})
+			if err != nil {
+				t.Errorf("ContentFacts() failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ContentFacts() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPluginInstance(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		input        string
+		wantContent  bool
+		wantDocument bool
+	}{
+		{
+			name:        "content only",
+			input:       "rules: [{name: x, match: {text: a}, facts: {correspondent: y}}]",
+			wantContent: true,
+		},
+		{
+			name: "document only",
+			input: `
+rules:
+  - name: invoice
+    match:
+      sketch: |` + indent(testSketch, "        ") + `
+    facts:
+      title: '{{ group "number" "value" }}'
+`,
+			wantDocument: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Parse([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			inst, err := f.PluginInfo().New()
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+
+			if _, ok := inst.(paperminer.ContentFacter); ok != tc.wantContent {
+				t.Errorf("Instance implements ContentFacter: %t, want %t", ok, tc.wantContent)
+			}
+
+			if _, ok := inst.(paperminer.DocumentFacter); ok != tc.wantDocument {
+				t.Errorf("Instance implements DocumentFacter: %t, want %t", ok, tc.wantDocument)
+			}
+		})
+	}
+}
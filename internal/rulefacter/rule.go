@@ -0,0 +1,265 @@
+package rulefacter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/factbuilder"
+	"github.com/hansmi/paperminer/internal/tmplargs"
+	"github.com/hansmi/paperminer/pkg/sketchfacts"
+)
+
+type rule struct {
+	name     string
+	priority int
+	facts    factsConfig
+
+	filename *regexp.Regexp
+
+	// Set for rules evaluated on the text content.
+	text    *regexp.Regexp
+	builder factbuilder.Builder
+
+	// Set for rules evaluated on the document file.
+	sketch *sketchfacts.Plugin
+}
+
+func compileRegexp(field, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", os.ErrInvalid, field, err)
+	}
+
+	return re, nil
+}
+
+func compileRule(cfg ruleConfig) (*rule, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", os.ErrInvalid)
+	}
+
+	r := &rule{
+		name:     cfg.Name,
+		priority: cfg.Priority,
+		facts:    cfg.Facts,
+	}
+
+	m := cfg.Match
+
+	if m.Filename == "" && m.Text == "" && m.Sketch == "" {
+		return nil, fmt.Errorf("%w: at least one match condition is required", os.ErrInvalid)
+	}
+
+	if m.Text != "" && m.Sketch != "" {
+		return nil, fmt.Errorf(`%w: "text" and "sketch" are mutually exclusive`, os.ErrInvalid)
+	}
+
+	if isEmptyFacts(cfg.Facts) {
+		return nil, fmt.Errorf("%w: no facts configured", os.ErrInvalid)
+	}
+
+	var err error
+
+	if r.filename, err = compileRegexp("filename", m.Filename); err != nil {
+		return nil, err
+	}
+
+	if m.Sketch != "" {
+		err = r.compileSketch(m)
+	} else {
+		if len(m.RequiredNodes) > 0 || m.Pages != "" {
+			return nil, fmt.Errorf("%w: required nodes and pages need a sketch", os.ErrInvalid)
+		}
+
+		err = r.compileText(m)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func isEmptyFacts(f factsConfig) bool {
+	return (f.Title == "" &&
+		f.Created == nil &&
+		f.Correspondent == "" &&
+		f.DocumentType == "" &&
+		f.StoragePath == "" &&
+		len(f.Tags) == 0)
+}
+
+func (r *rule) compileSketch(m matchConfig) error {
+	pages, err := sketchfacts.ParsePages(m.Pages)
+	if err != nil {
+		return err
+	}
+
+	mapping := &sketchfacts.Mapping{
+		Title:         r.facts.Title,
+		Correspondent: r.facts.Correspondent,
+		DocumentType:  r.facts.DocumentType,
+		StoragePath:   r.facts.StoragePath,
+		SetTags:       r.facts.Tags,
+	}
+
+	if c := r.facts.Created; c != nil {
+		if c.Node == "" {
+			return fmt.Errorf("%w: created date requires a node", os.ErrInvalid)
+		}
+
+		mapping.Created = &sketchfacts.DateMapping{
+			NodeGroup: sketchfacts.NodeGroup{Node: c.Node, Group: c.Group},
+			Layout:    c.Layout,
+			Locale:    c.Locale,
+		}
+	}
+
+	p, err := sketchfacts.New(sketchfacts.Options{
+		Name:      r.name,
+		Textproto: m.Sketch,
+		Pages:     pages,
+		Required:  m.RequiredNodes,
+		Mapping:   mapping,
+		Priority:  r.priority,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.sketch = p
+
+	return nil
+}
+
+// groupText returns the text of a named group in a match.
+func groupText(re *regexp.Regexp, match []string, name string) string {
+	if re != nil {
+		if idx := re.SubexpIndex(name); idx >= 0 && idx < len(match) {
+			return strings.TrimSpace(match[idx])
+		}
+	}
+
+	return ""
+}
+
+func textFuncs(re *regexp.Regexp, match []string) template.FuncMap {
+	return template.FuncMap{
+		"group": func(name string) string {
+			return groupText(re, match, name)
+		},
+		"trim": strings.TrimSpace,
+	}
+}
+
+func (r *rule) compileText(m matchConfig) error {
+	var err error
+
+	if r.text, err = compileRegexp("text", m.Text); err != nil {
+		return err
+	}
+
+	r.builder = factbuilder.Builder{
+		Correspondent: r.facts.Correspondent,
+		DocumentType:  r.facts.DocumentType,
+		StoragePath:   r.facts.StoragePath,
+		SetTags:       r.facts.Tags,
+	}
+
+	var groups []string
+
+	if r.facts.Title != "" {
+		r.builder.Title, err = template.New("title").
+			Funcs(textFuncs(nil, nil)).
+			Parse(r.facts.Title)
+		if err != nil {
+			return fmt.Errorf("%w: title template: %w", os.ErrInvalid, err)
+		}
+
+		groups = append(groups, tmplargs.FirstStrings(r.builder.Title, "group")...)
+	}
+
+	if c := r.facts.Created; c != nil {
+		if c.Node != "" {
+			return fmt.Errorf("%w: created date node requires a sketch", os.ErrInvalid)
+		}
+
+		if err := factbuilder.ValidateDate(c.Layout, c.Locale); err != nil {
+			return err
+		}
+
+		r.builder.CreatedLayout = c.Layout
+		r.builder.CreatedLocale = c.Locale
+
+		groups = append(groups, c.Group)
+	}
+
+	for _, name := range groups {
+		if r.text == nil || !slices.Contains(r.text.SubexpNames(), name) {
+			return fmt.Errorf("%w: group %q not found in text expression", os.ErrInvalid, name)
+		}
+	}
+
+	return nil
+}
+
+// matchFilename returns whether the original filename matches the rule.
+func (r *rule) matchFilename(info *paperminer.DocumentInfo) bool {
+	if r.filename == nil {
+		return true
+	}
+
+	return info != nil && r.filename.MatchString(info.OriginalFileName)
+}
+
+// contentFacts evaluates a rule without a sketch. Nil is returned if the rule
+// doesn't match.
+func (r *rule) contentFacts(info *paperminer.DocumentInfo, content string) (*paperminer.Facts, error) {
+	if !r.matchFilename(info) {
+		return nil, nil
+	}
+
+	var match []string
+
+	if r.text != nil {
+		if match = r.text.FindStringSubmatch(content); match == nil {
+			return nil, nil
+		}
+	}
+
+	var created string
+
+	if c := r.facts.Created; c != nil {
+		created = groupText(r.text, match, c.Group)
+	}
+
+	facts, err := r.builder.Build(textFuncs(r.text, match), created)
+	if err != nil {
+		return nil, err
+	}
+
+	facts.Priority = r.priority
+
+	return facts, nil
+}
+
+// documentFacts evaluates a rule with a sketch. Nil is returned if the rule
+// doesn't match.
+func (r *rule) documentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	if !r.matchFilename(opts.Info) {
+		return nil, nil
+	}
+
+	return r.sketch.DocumentFacts(ctx, opts)
+}
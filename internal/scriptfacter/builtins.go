@@ -0,0 +1,118 @@
+package scriptfacter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hansmi/paperminer/internal/localdate"
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// reMatch returns a struct with the matched text and the submatches, both by
+// index ("groups") and by name ("named"). Unmatched groups are None.
+func reMatch(re *regexp.Regexp, match []string, loc []int) starlark.Value {
+	groups := make(starlark.Tuple, len(match))
+	named := starlark.NewDict(len(match))
+
+	for idx, text := range match {
+		var value starlark.Value = starlark.None
+
+		if loc[2*idx] >= 0 {
+			value = starlark.String(text)
+		}
+
+		groups[idx] = value
+
+		if name := re.SubexpNames()[idx]; name != "" {
+			named.SetKey(starlark.String(name), value)
+		}
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"text":   groups[0],
+		"groups": groups,
+		"named":  named,
+	})
+}
+
+func reSearch(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var expr, text string
+
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &expr, "text", &text); err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	loc := re.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return starlark.None, nil
+	}
+
+	return reMatch(re, re.FindStringSubmatch(text), loc), nil
+}
+
+func reFindAll(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var expr, text string
+
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &expr, "text", &text); err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	matches := re.FindAllStringSubmatch(text, -1)
+	locs := re.FindAllStringSubmatchIndex(text, -1)
+
+	result := make([]starlark.Value, 0, len(matches))
+
+	for idx, match := range matches {
+		result = append(result, reMatch(re, match, locs[idx]))
+	}
+
+	return starlark.NewList(result), nil
+}
+
+var reModule = &starlarkstruct.Module{
+	Name: "re",
+	Members: starlark.StringDict{
+		"search":  starlark.NewBuiltin("re.search", reSearch),
+		"findall": starlark.NewBuiltin("re.findall", reFindAll),
+	},
+}
+
+func parseDate(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var layout, value, locale string
+
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "layout", &layout, "value", &value, "locale?", &locale); err != nil {
+		return nil, err
+	}
+
+	if !localdate.ValidLocale(locale) {
+		return nil, fmt.Errorf("%s: unsupported locale %q", b.Name(), locale)
+	}
+
+	ts, err := localdate.Parse(layout, locale, value)
+	if err != nil {
+		return starlark.None, nil
+	}
+
+	return starlarktime.Time(ts), nil
+}
+
+// predeclared contains the names available to all scripts in addition to the
+// Starlark built-ins.
+var predeclared = starlark.StringDict{
+	"struct":     starlark.NewBuiltin("struct", starlarkstruct.Make),
+	"re":         reModule,
+	"time":       starlarktime.Module,
+	"parse_date": starlark.NewBuiltin("parse_date", parseDate),
+}
@@ -0,0 +1,321 @@
+// Package scriptfacter implements a built-in facter running Starlark scripts
+// loaded from files. Scripts run sandboxed within the process: they can't
+// access files, the network or other modules, and their execution is
+// limited in steps and time.
+package scriptfacter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/pdftext"
+	"github.com/hansmi/paperminer/pkg/sketchfacts"
+	"github.com/hansmi/staticplug"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+	"go.uber.org/zap"
+)
+
+// NamePrefix is prepended to the script file name without extension to form
+// the facter name.
+const NamePrefix = "script:"
+
+const (
+	defaultTimeout  = 10 * time.Second
+	defaultMaxSteps = 10_000_000
+)
+
+type Options struct {
+	// Maximum amount of time for evaluating a document. Defaults to ten
+	// seconds.
+	Timeout time.Duration
+
+	// Maximum number of Starlark execution steps for evaluating a document,
+	// approximating the CPU time used. Defaults to ten million.
+	MaxSteps uint64
+}
+
+// Script is a facter evaluating a Starlark script. The script must define
+// a "facts" function receiving a struct describing the document and
+// returning a dict of facts or None.
+//
+// Scripts defining a "sketch" variable (dossier sketch in textproto format)
+// are evaluated on the document file and receive the sketch report. The
+// optional "pages" ("first", "last" or "all") and "required_nodes" variables
+// configure the evaluation. Other scripts receive the text content.
+type Script struct {
+	opts Options
+	name string
+	fn   *starlark.Function
+
+	// Set for scripts evaluated on the document file.
+	sketch *sketchfacts.Plugin
+
+	// Returns the text of a range of pages, one element per page.
+	pageText func(ctx context.Context, path string, first, last int) ([]string, error)
+}
+
+var _ staticplug.Plugin = (*Script)(nil)
+
+// Name returns the facter name for a script file.
+func Name(path string) string {
+	base := filepath.Base(path)
+
+	return NamePrefix + strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func newThread(name string, logger *zap.Logger, maxSteps uint64) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			logger.Info("Script output", zap.String("script", name), zap.String("message", msg))
+		},
+	}
+
+	thread.SetMaxExecutionSteps(maxSteps)
+
+	return thread
+}
+
+// run executes a function with the configured limits.
+func (s *Script) run(ctx context.Context, thread *starlark.Thread, fn func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, func() {
+		thread.Cancel(ctx.Err().Error())
+	})
+	defer stop()
+
+	err := fn()
+
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w (%w)", err, ctxErr)
+		}
+	}
+
+	return err
+}
+
+func stringGlobal(globals starlark.StringDict, name string) (string, error) {
+	value, ok := globals[name]
+	if !ok {
+		return "", nil
+	}
+
+	s, ok := starlark.AsString(value)
+	if !ok {
+		return "", fmt.Errorf("%w: %q must be a string, got %s", os.ErrInvalid, name, value.Type())
+	}
+
+	return s, nil
+}
+
+// Parse compiles and initializes a script. The name is used for the facter
+// and in error messages.
+func Parse(name string, src []byte, opts Options) (*Script, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	if opts.MaxSteps == 0 {
+		opts.MaxSteps = defaultMaxSteps
+	}
+
+	s := &Script{
+		opts:     opts,
+		name:     name,
+		pageText: pdftext.Pages,
+	}
+
+	var globals starlark.StringDict
+
+	thread := newThread(name, zap.NewNop(), opts.MaxSteps)
+
+	if err := s.run(context.Background(), thread, func() (err error) {
+		globals, err = starlark.ExecFileOptions(&syntax.FileOptions{}, thread, name, src, predeclared)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %w", os.ErrInvalid, err)
+	}
+
+	fn, ok := globals["facts"].(*starlark.Function)
+	if !ok {
+		return nil, fmt.Errorf(`%w: function "facts" is not defined`, os.ErrInvalid)
+	}
+
+	if fn.NumParams() != 1 {
+		return nil, fmt.Errorf(`%w: function "facts" must take exactly one parameter`, os.ErrInvalid)
+	}
+
+	s.fn = fn
+
+	if err := s.compileSketch(globals); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Script) compileSketch(globals starlark.StringDict) error {
+	textproto, err := stringGlobal(globals, "sketch")
+	if err != nil {
+		return err
+	}
+
+	pagesName, err := stringGlobal(globals, "pages")
+	if err != nil {
+		return err
+	}
+
+	var required []string
+
+	if value, ok := globals["required_nodes"]; ok {
+		if required, err = toStrings(value); err != nil {
+			return fmt.Errorf(`%w: "required_nodes": %w`, os.ErrInvalid, err)
+		}
+	}
+
+	if textproto == "" {
+		if pagesName != "" || len(required) > 0 {
+			return fmt.Errorf("%w: pages and required nodes need a sketch", os.ErrInvalid)
+		}
+
+		return nil
+	}
+
+	pages, err := sketchfacts.ParsePages(pagesName)
+	if err != nil {
+		return err
+	}
+
+	s.sketch, err = sketchfacts.New(sketchfacts.Options{
+		Name:          s.name,
+		Textproto:     textproto,
+		Pages:         pages,
+		Required:      required,
+		BuildDocument: s.buildDocument,
+	})
+
+	return err
+}
+
+// Load reads and compiles a script file.
+func Load(path string, opts Options) (*Script, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Parse(Name(path), src, opts)
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+func (s *Script) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: s.name,
+		New: func() (staticplug.Plugin, error) {
+			// Instances are stateless.
+			if s.sketch != nil {
+				return documentFacter{s}, nil
+			}
+
+			return contentFacter{s}, nil
+		},
+	}
+}
+
+// call invokes the "facts" function of the script. Invalid return values are
+// reported as permanent errors as they'd recur on retries. Other errors, e.g.
+// from failing or timed out scripts, are returned as-is.
+func (s *Script) call(ctx context.Context, logger *zap.Logger, doc starlark.StringDict) (*paperminer.Facts, error) {
+	var result starlark.Value
+
+	thread := newThread(s.name, logger, s.opts.MaxSteps)
+
+	arg := starlarkstruct.FromStringDict(starlarkstruct.Default, doc)
+
+	err := s.run(ctx, thread, func() (err error) {
+		result, err = starlark.Call(thread, s.fn, starlark.Tuple{arg}, nil)
+		return err
+	})
+
+	if err == nil {
+		var facts *paperminer.Facts
+
+		if facts, err = toFacts(result); err == nil {
+			return facts, nil
+		}
+
+		err = paperminer.Permanent(err)
+	}
+
+	return nil, fmt.Errorf("script %s: %w", s.name, err)
+}
+
+// buildDocument calls the script with the sketch report and the text of the
+// evaluated pages.
+func (s *Script) buildDocument(ctx context.Context, opts paperminer.DocumentFacterOptions, report *sketchfacts.Report) (*paperminer.Facts, error) {
+	var texts []string
+
+	if len(report.Pages) > 0 {
+		var err error
+
+		// Evaluated pages are consecutive.
+		first := report.Pages[0].Number
+		last := report.Pages[len(report.Pages)-1].Number
+
+		if texts, err = s.pageText(ctx, opts.Path, first, last); err != nil {
+			return nil, fmt.Errorf("script %s: page text: %w", s.name, err)
+		}
+	}
+
+	return s.call(ctx, opts.Logger, starlark.StringDict{
+		"info":    infoValue(opts.Info),
+		"content": starlark.String(strings.Join(texts, "\n")),
+		"sketch":  reportValue(report, texts),
+	})
+}
+
+type contentFacter struct {
+	s *Script
+}
+
+var _ paperminer.ContentFacter = contentFacter{}
+
+func (c contentFacter) PluginInfo() staticplug.PluginInfo {
+	return c.s.PluginInfo()
+}
+
+func (c contentFacter) ContentFacts(ctx context.Context, opts paperminer.ContentFacterOptions) (*paperminer.Facts, error) {
+	return c.s.call(ctx, opts.Logger, starlark.StringDict{
+		"info":    infoValue(opts.Info),
+		"content": starlark.String(opts.Content),
+		"sketch":  starlark.None,
+	})
+}
+
+type documentFacter struct {
+	s *Script
+}
+
+var _ paperminer.DocumentFacter = documentFacter{}
+
+func (d documentFacter) PluginInfo() staticplug.PluginInfo {
+	return d.s.PluginInfo()
+}
+
+func (d documentFacter) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	return d.s.sketch.DocumentFacts(ctx, opts)
+}
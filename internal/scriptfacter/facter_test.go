@@ -0,0 +1,406 @@
+package scriptfacter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/dossier/pkg/sketch"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"github.com/hansmi/paperminer/internal/testutil"
+	"github.com/hansmi/paperminer/pkg/sketchfacts"
+	"go.uber.org/zap/zaptest"
+)
+
+const testSketch = `
+nodes {
+  name: "number"
+  search_areas {
+    top_left { abs { left { cm: 1 } top { cm: 1 } } }
+    width { cm: 10 }
+    height { cm: 10 }
+  }
+  line_text { regex: "(?P<value>\\d+)" }
+}
+`
+
+func TestName(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{"acme.star", "script:acme"},
+		{"/etc/paperminer/invoice.v2.star", "script:invoice.v2"},
+		{"noext", "script:noext"},
+	} {
+		if got := Name(tc.path); got != tc.want {
+			t.Errorf("Name(%q) = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		src          string
+		wantErr      error
+		wantDocument bool
+	}{
+		{
+			name: "content",
+			src: `
+def facts(doc):
+    return None
+`,
+		},
+		{
+			name: "sketch",
+			src: `
+sketch = r"""` + testSketch + `"""
+pages = "all"
+required_nodes = ["number"]
+
+def facts(doc):
+    return {"title": doc.sketch.group("number", "value")}
+`,
+			wantDocument: true,
+		},
+		{
+			name:    "syntax error",
+			src:     "def facts(doc)\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "missing function",
+			src:     "x = 1\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "wrong parameters",
+			src:     "def facts(a, b):\n    pass\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "load disallowed",
+			src:     "load('other.star', 'x')\ndef facts(doc):\n    pass\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "top-level failure",
+			src:     "fail('broken')\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "pages without sketch",
+			src:     "pages = 'all'\ndef facts(doc):\n    pass\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "unknown pages",
+			src:     "sketch = r'''" + testSketch + "'''\npages = 'middle'\ndef facts(doc):\n    pass\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "sketch not a string",
+			src:     "sketch = 1\ndef facts(doc):\n    pass\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "too many steps",
+			src:     "[x for x in range(1000000000)]\ndef facts(doc):\n    pass\n",
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse("script:test", []byte(tc.src), Options{MaxSteps: 100_000})
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Fatalf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err != nil {
+				return
+			}
+
+			inst, err := s.PluginInfo().New()
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+
+			if _, ok := inst.(paperminer.DocumentFacter); ok != tc.wantDocument {
+				t.Errorf("Instance implements DocumentFacter: %t, want %t", ok, tc.wantDocument)
+			}
+
+			if _, ok := inst.(paperminer.ContentFacter); ok == tc.wantDocument {
+				t.Errorf("Instance implements ContentFacter: %t, want %t", ok, !tc.wantDocument)
+			}
+		})
+	}
+}
+
+func TestContentFacts(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		src           string
+		info          paperminer.DocumentInfo
+		content       string
+		want          *paperminer.Facts
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{
+			name: "no match",
+			src: `
+def facts(doc):
+    if not re.search(r"ACME", doc.content):
+        return None
+    return {"correspondent": "ACME"}
+`,
+			content: "Other Corp",
+		},
+		{
+			name: "all facts",
+			src: `
+def facts(doc):
+    m = re.search(r"Invoice (?P<number>\d+) from (?P<date>.+)", doc.content)
+    if not m:
+        return None
+
+    tags = ["invoice"]
+    if doc.info.original_file_name.endswith(".pdf"):
+        tags.append("pdf")
+
+    return {
+        "priority": 5,
+        "suggest": True,
+        "force": ["title"],
+        "title": "ACME invoice " + m.named["number"],
+        "created": parse_date("2. January 2006", m.named["date"], locale = "de"),
+        "correspondent": "ACME",
+        "document_type": "Invoice",
+        "storage_path": None,
+        "set_tags": tags,
+        "unset_tags": ("inbox",),
+    }
+`,
+			info:    paperminer.DocumentInfo{OriginalFileName: "scan.pdf"},
+			content: "Invoice 1234 from 3. März 2024",
+			want: &paperminer.Facts{
+				Priority:      5,
+				Suggest:       true,
+				Force:         []string{"title"},
+				Title:         ref.Ref("ACME invoice 1234"),
+				Created:       ref.Ref(time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)),
+				Correspondent: ref.Ref("ACME"),
+				DocumentType:  ref.Ref("Invoice"),
+				SetTags:       []string{"invoice", "pdf"},
+				UnsetTags:     []string{"inbox"},
+			},
+		},
+		{
+			name: "info",
+			src: `
+def facts(doc):
+    return {
+        "title": "%d %s %s" % (doc.info.id, doc.info.tags, doc.info.correspondent),
+        "created": doc.info.added,
+    }
+`,
+			info: paperminer.DocumentInfo{
+				ID:    12,
+				Tags:  []string{"a", "b"},
+				Added: time.Date(2020, time.May, 1, 12, 0, 0, 0, time.UTC),
+			},
+			want: &paperminer.Facts{
+				Title:   ref.Ref(`12 ("a", "b") None`),
+				Created: ref.Ref(time.Date(2020, time.May, 1, 12, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name: "findall",
+			src: `
+def facts(doc):
+    return {"set_tags": [m.groups[1] for m in re.findall(r"#(\w+)", doc.content)]}
+`,
+			content: "#one and #two",
+			want: &paperminer.Facts{
+				SetTags: []string{"one", "two"},
+			},
+		},
+		{
+			name: "date string",
+			src:  "def facts(doc):\n    return {'created': '2021-02-03'}\n",
+			want: &paperminer.Facts{Created: ref.Ref(time.Date(2021, time.February, 3, 0, 0, 0, 0, time.UTC))},
+		},
+		{
+			name:          "unknown key",
+			src:           "def facts(doc):\n    return {'name': 'x'}\n",
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name:          "wrong type",
+			src:           "def facts(doc):\n    return {'title': 1}\n",
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name:          "wrong return value",
+			src:           "def facts(doc):\n    return 'ACME'\n",
+			wantErr:       true,
+			wantPermanent: true,
+		},
+		{
+			name:    "failure",
+			src:     "def facts(doc):\n    fail('no')\n",
+			wantErr: true,
+		},
+		{
+			name:    "frozen globals",
+			src:     "seen = []\ndef facts(doc):\n    seen.append(1)\n",
+			wantErr: true,
+		},
+		{
+			name:    "too many steps",
+			src:     "def facts(doc):\n    [x for x in range(1000000000)]\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse("script:test", []byte(tc.src), Options{MaxSteps: 100_000})
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			got, err := contentFacter{s}.ContentFacts(context.Background(), paperminer.ContentFacterOptions{
+				Logger:  zaptest.NewLogger(t),
+				Info:    &tc.info,
+				Content: tc.content,
+			})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ContentFacts() returned error %v, want error %t", err, tc.wantErr)
+			}
+
+			if got := errors.Is(err, paperminer.ErrPermanent); got != tc.wantPermanent {
+				t.Errorf("Error %v is permanent: %t, want %t", err, got, tc.wantPermanent)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Facts diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildDocument(t *testing.T) {
+	errTest := errors.New("test error")
+
+	src := "sketch = r\"\"\"" + testSketch + "\"\"\"\npages = 'all'\n" +
+		"def facts(doc):\n    return {'title': doc.sketch.texts[-1] + ' / ' + doc.content}\n"
+
+	report := &sketchfacts.Report{
+		Pages: []sketchfacts.PageReport{
+			{PageReport: &sketch.PageReport{}, Number: 2},
+			{PageReport: &sketch.PageReport{}, Number: 3},
+		},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		texts   []string
+		err     error
+		want    *paperminer.Facts
+		wantErr error
+	}{
+		{
+			name:  "texts",
+			texts: []string{"second", "third"},
+			want:  &paperminer.Facts{Title: ref.Ref("third / second\nthird")},
+		},
+		{
+			name:    "failure",
+			err:     errTest,
+			wantErr: errTest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse("script:test", []byte(src), Options{})
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			s.pageText = func(_ context.Context, path string, first, last int) ([]string, error) {
+				if path != "doc.pdf" || first != 2 || last != 3 {
+					t.Errorf("Got text request for %q, pages %d to %d", path, first, last)
+				}
+
+				return tc.texts, tc.err
+			}
+
+			got, err := s.buildDocument(context.Background(), paperminer.DocumentFacterOptions{
+				Logger: zaptest.NewLogger(t),
+				Info:   &paperminer.DocumentInfo{},
+				Path:   "doc.pdf",
+				Format: paperminer.FormatPDF,
+			}, report)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if errors.Is(err, paperminer.ErrPermanent) {
+				t.Errorf("Error is permanent: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Facts diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s, err := Parse("script:test", []byte("def facts(doc):\n    [x for x in range(1000000000)]\n"), Options{
+		Timeout:  10 * time.Millisecond,
+		MaxSteps: 1 << 62,
+	})
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	_, err = contentFacter{s}.ContentFacts(context.Background(), paperminer.ContentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ContentFacts() returned %v, want deadline exceeded", err)
+	}
+
+	if errors.Is(err, paperminer.ErrPermanent) {
+		t.Errorf("Timeout is permanent: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "acme.star"), "def facts(doc):\n    pass\n")
+
+	s, err := Load(path, Options{})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := s.PluginInfo().Name; got != "script:acme" {
+		t.Errorf("Name %q, want %q", got, "script:acme")
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.star"), Options{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of missing file returned %v, want not-exist error", err)
+	}
+}
@@ -0,0 +1,248 @@
+package scriptfacter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/pkg/sketchfacts"
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+func optionalString(s string) starlark.Value {
+	if s == "" {
+		return starlark.None
+	}
+
+	return starlark.String(s)
+}
+
+func optionalTime(ts time.Time) starlark.Value {
+	if ts.IsZero() {
+		return starlark.None
+	}
+
+	return starlarktime.Time(ts)
+}
+
+func stringTuple(values []string) starlark.Tuple {
+	result := make(starlark.Tuple, 0, len(values))
+
+	for _, i := range values {
+		result = append(result, starlark.String(i))
+	}
+
+	return result
+}
+
+// infoValue converts the document information to a struct. Unset values are
+// None.
+func infoValue(info *paperminer.DocumentInfo) starlark.Value {
+	if info == nil {
+		info = &paperminer.DocumentInfo{}
+	}
+
+	var asn starlark.Value = starlark.None
+
+	if info.ArchiveSerialNumber != nil {
+		asn = starlark.MakeInt64(*info.ArchiveSerialNumber)
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"id":                    starlark.MakeInt64(info.ID),
+		"title":                 starlark.String(info.Title),
+		"created":               optionalTime(info.Created),
+		"modified":              optionalTime(info.Modified),
+		"added":                 optionalTime(info.Added),
+		"archive_serial_number": asn,
+		"original_file_name":    starlark.String(info.OriginalFileName),
+		"original_mime_type":    starlark.String(info.OriginalMimeType),
+		"language":              optionalString(info.Language),
+		"tags":                  stringTuple(info.Tags),
+		"correspondent":         optionalString(info.Correspondent),
+		"document_type":         optionalString(info.DocumentType),
+		"storage_path":          optionalString(info.StoragePath),
+		"owner":                 optionalString(info.Owner),
+	})
+}
+
+// reportValue converts a sketch report to a struct with functions to access
+// nodes. The text of the evaluated pages is given in page order.
+func reportValue(report *sketchfacts.Report, texts []string) starlark.Value {
+	pages := make(starlark.Tuple, 0, len(report.Pages))
+	pageTexts := make(starlark.Tuple, 0, len(report.Pages))
+
+	for idx, page := range report.Pages {
+		var text string
+
+		if idx < len(texts) {
+			text = texts[idx]
+		}
+
+		pages = append(pages, starlark.MakeInt(page.Number))
+		pageTexts = append(pageTexts, starlark.String(text))
+	}
+
+	group := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var node, name string
+
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &node, &name); err != nil {
+			return nil, err
+		}
+
+		if text, ok := report.GroupText(node, name); ok {
+			return starlark.String(text), nil
+		}
+
+		return starlark.None, nil
+	}
+
+	valid := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var node string
+
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &node); err != nil {
+			return nil, err
+		}
+
+		return starlark.Bool(report.ValidNode(node) != nil), nil
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"pages":      pages,
+		"texts":      pageTexts,
+		"page_count": starlark.MakeInt(report.PageCount),
+		"group":      starlark.NewBuiltin("group", group),
+		"valid":      starlark.NewBuiltin("valid", valid),
+	})
+}
+
+func toOptionalString(value starlark.Value) (*string, error) {
+	s, ok := starlark.AsString(value)
+	if !ok {
+		return nil, fmt.Errorf("got %s, want string", value.Type())
+	}
+
+	if s == "" {
+		return nil, nil
+	}
+
+	return &s, nil
+}
+
+func toStrings(value starlark.Value) ([]string, error) {
+	iterable, ok := value.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("got %s, want list of strings", value.Type())
+	}
+
+	var result []string
+
+	iter := iterable.Iterate()
+	defer iter.Done()
+
+	var item starlark.Value
+
+	for iter.Next(&item) {
+		s, ok := starlark.AsString(item)
+		if !ok {
+			return nil, fmt.Errorf("got %s element, want string", item.Type())
+		}
+
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+// toTime accepts a time value or a string in "YYYY-MM-DD" or RFC 3339 format.
+func toTime(value starlark.Value) (*time.Time, error) {
+	var ts time.Time
+
+	switch v := value.(type) {
+	case starlarktime.Time:
+		ts = time.Time(v)
+
+	case starlark.String:
+		var err error
+
+		if ts, err = time.Parse(time.DateOnly, string(v)); err != nil {
+			if ts, err = time.Parse(time.RFC3339, string(v)); err != nil {
+				return nil, fmt.Errorf("%q is neither a date nor in RFC 3339 format", string(v))
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("got %s, want time or string", value.Type())
+	}
+
+	return &ts, nil
+}
+
+// toFacts converts the return value of a script. None reports no facts. Dicts
+// use the same keys as the JSON encoding of [paperminer.Facts], except for
+// custom fields.
+func toFacts(value starlark.Value) (*paperminer.Facts, error) {
+	if value == starlark.None {
+		return nil, nil
+	}
+
+	dict, ok := value.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("script returned %s, want dict or None", value.Type())
+	}
+
+	facts := &paperminer.Facts{}
+
+	var err error
+
+	for _, item := range dict.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("got %s key, want string", item[0].Type())
+		}
+
+		value := item[1]
+
+		if value == starlark.None {
+			continue
+		}
+
+		switch key {
+		case "priority":
+			facts.Priority, err = starlark.AsInt32(value)
+		case "suggest":
+			if b, ok := value.(starlark.Bool); ok {
+				facts.Suggest = bool(b)
+			} else {
+				err = fmt.Errorf("got %s, want bool", value.Type())
+			}
+		case "force":
+			facts.Force, err = toStrings(value)
+		case "title":
+			facts.Title, err = toOptionalString(value)
+		case "created":
+			facts.Created, err = toTime(value)
+		case "document_type":
+			facts.DocumentType, err = toOptionalString(value)
+		case "correspondent":
+			facts.Correspondent, err = toOptionalString(value)
+		case "storage_path":
+			facts.StoragePath, err = toOptionalString(value)
+		case "set_tags":
+			facts.SetTags, err = toStrings(value)
+		case "unset_tags":
+			facts.UnsetTags, err = toStrings(value)
+		default:
+			err = errors.New("unknown key")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("facts %q: %w", key, err)
+		}
+	}
+
+	return facts, nil
+}
@@ -20,8 +20,10 @@ func Open(path string, timeout time.Duration) (*bolthold.Store, error) {
 		return nil, fmt.Errorf("store: %w", err)
 	}
 
-	if err := db.ReIndex(&DocumentTask{}, nil); err != nil {
-		return nil, fmt.Errorf("store indexing: %w", err)
+	for _, dataType := range []any{&DocumentTask{}, &Suggestion{}} {
+		if err := db.ReIndex(dataType, nil); err != nil {
+			return nil, fmt.Errorf("store indexing: %w", err)
+		}
 	}
 
 	return db, nil
@@ -10,15 +10,11 @@ import (
 	"go.uber.org/zap"
 )
 
-func Prune(ctx context.Context, logger *zap.Logger, s *bolthold.Store, deleteUpdatedBefore time.Time) error {
-	query := bolthold.Where("RecordUpdated").Lt(deleteUpdatedBefore)
-
+func pruneMatching(s *bolthold.Store, logger *zap.Logger, dataType any, query *bolthold.Query) error {
 	return s.Bolt().Update(func(tx *bbolt.Tx) error {
-		var dataType DocumentTask
-
 		count, err := s.TxCount(tx, dataType, query)
 		if err != nil {
-			return fmt.Errorf("counting tasks: %w", err)
+			return fmt.Errorf("counting records: %w", err)
 		}
 		if count == 0 {
 			return nil
@@ -26,7 +22,8 @@ func Prune(ctx context.Context, logger *zap.Logger, s *bolthold.Store, deleteUpd
 
 		logger.Debug("Delete obsolete records from store",
 			zap.Int("count", count),
-			zap.Time("updated_before", deleteUpdatedBefore),
+			zap.String("type", fmt.Sprintf("%T", dataType)),
+			zap.Stringer("query", query),
 		)
 
 		if err := s.TxDeleteMatching(tx, dataType, query); err != nil {
@@ -36,3 +33,13 @@ func Prune(ctx context.Context, logger *zap.Logger, s *bolthold.Store, deleteUpd
 		return nil
 	})
 }
+
+func Prune(ctx context.Context, logger *zap.Logger, s *bolthold.Store, deleteUpdatedBefore time.Time) error {
+	return pruneMatching(s, logger, DocumentTask{}, bolthold.Where("RecordUpdated").Lt(deleteUpdatedBefore))
+}
+
+// PruneSuggestions deletes suggestions which were neither approved nor
+// replaced. Approving them remains possible using the document note.
+func PruneSuggestions(ctx context.Context, logger *zap.Logger, s *bolthold.Store, deleteCreatedBefore time.Time) error {
+	return pruneMatching(s, logger, Suggestion{}, bolthold.Where("RecordCreated").Lt(deleteCreatedBefore))
+}
@@ -45,3 +45,46 @@ func TestPrune(t *testing.T) {
 		t.Errorf("Counted %d records after Prune(), want %d", got, want)
 	}
 }
+
+func TestPruneSuggestions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+
+	s, err := Open(filepath.Join(t.TempDir(), "file"), 0)
+	if err != nil {
+		t.Errorf("Open() failed: %v", err)
+	}
+
+	logger := zaptest.NewLogger(t)
+
+	start := time.Date(2023, time.January, 1, 1, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 10; i++ {
+		if err := s.Insert(int64(i), Suggestion{
+			ID:            int64(i),
+			RecordCreated: start.Add(time.Duration(i) * 24 * time.Hour),
+		}); err != nil {
+			t.Errorf("Insert(%d) failed: %v", i, err)
+		}
+	}
+
+	if err := s.Insert(1000, DocumentTask{RecordUpdated: start}); err != nil {
+		t.Errorf("Insert() failed: %v", err)
+	}
+
+	if err := PruneSuggestions(ctx, logger, s, start.Add(3*24*time.Hour)); err != nil {
+		t.Errorf("PruneSuggestions() failed: %v", err)
+	}
+
+	if got, err := s.Count(Suggestion{}, nil); err != nil {
+		t.Errorf("Count() failed: %v", err)
+	} else if want := 7; got != want {
+		t.Errorf("Counted %d suggestions after PruneSuggestions(), want %d", got, want)
+	}
+
+	if got, err := s.Count(DocumentTask{}, nil); err != nil {
+		t.Errorf("Count() failed: %v", err)
+	} else if got != 1 {
+		t.Errorf("Counted %d tasks after PruneSuggestions(), want 1", got)
+	}
+}
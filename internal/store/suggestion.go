@@ -0,0 +1,22 @@
+package store
+
+import (
+	"time"
+
+	"github.com/hansmi/paperminer"
+)
+
+// Suggestion holds facts proposed for a document until they're approved.
+// Records are keyed by the document ID.
+type Suggestion struct {
+	// Document ID
+	ID int64
+
+	Facts paperminer.Facts
+
+	// Document file checksums at the time the facts were extracted.
+	OriginalChecksum string
+	ArchiveChecksum  string
+
+	RecordCreated time.Time `boltholdIndex:""`
+}
@@ -0,0 +1,70 @@
+// Package tmplargs inspects the parse tree of Go templates.
+package tmplargs
+
+import (
+	"slices"
+	"text/template"
+	"text/template/parse"
+)
+
+// FirstStrings returns the first arguments of all calls to the named functions
+// in a template if they're string constants.
+func FirstStrings(tmpl *template.Template, funcs ...string) []string {
+	var result []string
+	var walk func(parse.Node)
+
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, i := range n.Nodes {
+					walk(i)
+				}
+			}
+
+		case *parse.ActionNode:
+			walk(n.Pipe)
+
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+
+		case *parse.PipeNode:
+			if n != nil {
+				for _, cmd := range n.Cmds {
+					walk(cmd)
+				}
+			}
+
+		case *parse.CommandNode:
+			if len(n.Args) > 1 {
+				if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && slices.Contains(funcs, ident.Ident) {
+					if s, ok := n.Args[1].(*parse.StringNode); ok {
+						result = append(result, s.Text)
+					}
+				}
+			}
+
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		}
+	}
+
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root)
+	}
+
+	return result
+}
@@ -0,0 +1,37 @@
+package tmplargs
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestFirstStrings(t *testing.T) {
+	funcs := template.FuncMap{
+		"first":  func(...string) string { return "" },
+		"second": func(string) bool { return false },
+		"other":  func(string) string { return "" },
+	}
+
+	for _, tc := range []struct {
+		text string
+		want []string
+	}{
+		{text: "Hello World"},
+		{text: `{{ first "a" "b" }}`, want: []string{"a"}},
+		{text: `{{ other "a" }}{{ first }}`},
+		{text: `{{ if second "a" }}{{ first "b" }}{{ else }}{{ first "c" | other }}{{ end }}`, want: []string{"a", "b", "c"}},
+		{text: `{{ with first "a" }}{{ . }}{{ end }}{{ range $x := "" }}{{ first (other "b") "c" }}{{ end }}`, want: []string{"a"}},
+		{text: `{{ $v := "x" }}{{ first $v }}`},
+	} {
+		tmpl := template.Must(template.New("").Funcs(funcs).Parse(tc.text))
+
+		got := FirstStrings(tmpl, "first", "second")
+
+		if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("FirstStrings(%q) diff (-want +got):\n%s", tc.text, diff)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-chi/chi/v5"
 	plclient "github.com/hansmi/paperhooks/pkg/client"
+	"github.com/hansmi/paperminer/internal/facter"
 	"github.com/hansmi/paperminer/internal/objectresolver"
 	"github.com/hansmi/staticplug"
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,10 +23,15 @@ type Environment interface {
 	App() *kingpin.Application
 
 	PluginRegistry() *staticplug.Registry
+	FacterOptions() facter.Options
 	MetricsRegistry() prometheus.Registerer
 
 	Mux() *chi.Mux
 
+	// AuthenticatedMux returns a router for endpoints requiring the webhook
+	// secret. Requests are rejected if no secret is configured.
+	AuthenticatedMux() chi.Router
+
 	Store() *bolthold.Store
 	Client() *plclient.Client
 	Resolvers() *objectresolver.ObjectResolvers
@@ -39,5 +45,30 @@ type Workflow interface {
 }
 
 type NotificationReceiver interface {
-	NotifyPostConsume()
+	// NotifyPostConsume is invoked after Paperless consumed a document. The
+	// document ID is zero if unknown.
+	NotifyPostConsume(documentID int64)
+}
+
+// Triggers of document events.
+const (
+	// Paperless added a newly consumed document.
+	TriggerAdded = "added"
+
+	// A document was changed.
+	TriggerUpdated = "updated"
+)
+
+// DocumentEvent describes a change to a document reported by Paperless.
+type DocumentEvent struct {
+	DocumentID int64
+
+	// Type of change, e.g. [TriggerAdded].
+	Trigger string
+}
+
+type DocumentEventReceiver interface {
+	// NotifyDocumentEvent is invoked for document events received from
+	// Paperless workflows.
+	NotifyDocumentEvent(DocumentEvent)
 }
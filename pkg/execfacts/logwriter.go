@@ -0,0 +1,74 @@
+package execfacts
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Lines longer than this are logged in multiple parts.
+const maxLineLength = 4096
+
+// logWriter logs every line written to it.
+type logWriter struct {
+	logger *zap.Logger
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+var _ io.WriteCloser = (*logWriter)(nil)
+
+func newLogWriter(logger *zap.Logger) *logWriter {
+	return &logWriter{
+		logger: logger,
+	}
+}
+
+func (w *logWriter) log(line []byte) {
+	w.logger.Info("Facter output", zap.ByteString("stderr", bytes.TrimRight(line, "\r")))
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+
+		if idx < 0 {
+			if len(w.buf) < maxLineLength {
+				break
+			}
+
+			idx = maxLineLength
+		}
+
+		w.log(w.buf[:idx])
+
+		if idx < len(w.buf) && w.buf[idx] == '\n' {
+			idx++
+		}
+
+		w.buf = w.buf[idx:]
+	}
+
+	return len(p), nil
+}
+
+// Close logs any remaining incomplete line.
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+
+	return nil
+}
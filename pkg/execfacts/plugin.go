@@ -0,0 +1,175 @@
+// Package execfacts implements a document facter running an external
+// program. The program receives a JSON request on stdin and writes facts in
+// JSON format to stdout.
+package execfacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/staticplug"
+	"go.uber.org/zap"
+)
+
+const defaultTimeout = time.Minute
+
+type Options struct {
+	Name string
+
+	// Program and its arguments.
+	Command []string
+
+	// Additional environment variables in "KEY=VALUE" format. The program
+	// inherits the environment of the current process.
+	Env []string
+
+	// Maximum amount of time for a single invocation. Defaults to one
+	// minute.
+	Timeout time.Duration
+
+	// Priority assigned to reported facts unless set by the program (see
+	// [paperminer.Facts.Priority]).
+	Priority int
+}
+
+// Request is written to the standard input of the program.
+type Request struct {
+	// Path to the local document file.
+	Path string `json:"path"`
+
+	// Format of the document file as a MIME type (see
+	// [paperminer.DocumentFacterOptions.Format]).
+	Format string `json:"format"`
+
+	// Barcodes decoded from the document pages, if enabled.
+	Barcodes []paperminer.Barcode `json:"barcodes,omitempty"`
+
+	Document *paperminer.DocumentInfo `json:"document"`
+}
+
+type Plugin struct {
+	opts Options
+}
+
+var _ staticplug.Plugin = (*Plugin)(nil)
+var _ paperminer.FormatsFacter = (*Plugin)(nil)
+
+// New creates a facter running a program for each document. The program must
+// write a JSON-encoded [paperminer.Facts] object or "null" to stdout. Output
+// on stderr is logged. A non-zero exit status is reported as an error.
+func New(opts Options) (*Plugin, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", os.ErrInvalid)
+	}
+
+	if len(opts.Command) == 0 || opts.Command[0] == "" {
+		return nil, fmt.Errorf("%w: command is required", os.ErrInvalid)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	return &Plugin{
+		opts: opts,
+	}, nil
+}
+
+func MustNew(opts Options) *Plugin {
+	p, err := New(opts)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Plugin) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: p.opts.Name,
+		New: func() (staticplug.Plugin, error) {
+			// Instances are stateless.
+			return p, nil
+		},
+	}
+}
+
+// decodeFacts parses the program output. Empty output and "null" report no
+// facts.
+func decodeFacts(r io.Reader) (*paperminer.Facts, error) {
+	var facts *paperminer.Facts
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&facts); errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("trailing data after facts")
+	}
+
+	return facts, nil
+}
+
+// Formats returns all supported formats. The program is told the format in the
+// request.
+func (p *Plugin) Formats() []string {
+	return []string{paperminer.FormatPDF, paperminer.FormatText, paperminer.FormatMail}
+}
+
+func (p *Plugin) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	request, err := json.Marshal(Request{
+		Path:     opts.Path,
+		Format:   opts.Format,
+		Barcodes: opts.Barcodes,
+		Document: opts.Info,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+
+	stderr := newLogWriter(opts.Logger.With(zap.Strings("command", p.opts.Command)))
+	defer stderr.Close()
+
+	cmd := exec.CommandContext(ctx, p.opts.Command[0], p.opts.Command[1:]...)
+	cmd.Env = append(os.Environ(), p.opts.Env...)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w (%w)", err, ctxErr)
+		}
+
+		return nil, fmt.Errorf("running %q: %w", p.opts.Command[0], err)
+	}
+
+	facts, err := decodeFacts(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("decoding output of %q: %w", p.opts.Command[0], err)
+	}
+
+	if facts != nil && facts.Priority == 0 {
+		facts.Priority = p.opts.Priority
+	}
+
+	return facts, nil
+}
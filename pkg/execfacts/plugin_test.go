@@ -0,0 +1,190 @@
+package execfacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const helperEnv = "EXECFACTS_TEST_HELPER"
+
+// TestHelperProcess is not a real test. It's invoked as the external program
+// by other tests.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv(helperEnv)
+	if mode == "" {
+		return
+	}
+
+	var req Request
+
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "decoding request: %v\n", err)
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "facts":
+		fmt.Fprintln(os.Stderr, "processing", req.Path)
+		fmt.Fprintf(os.Stdout, `{"title": %q, "correspondent": %q, "document_type": %q}`, req.Document.Title, req.Path, req.Format)
+	case "null":
+		fmt.Fprint(os.Stdout, "null")
+	case "empty":
+	case "invalid":
+		fmt.Fprint(os.Stdout, `{"unknown": 1}`)
+	case "fail":
+		fmt.Fprint(os.Stderr, "failure")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+
+	os.Exit(0)
+}
+
+func newTestPlugin(t *testing.T, mode string, timeout time.Duration) *Plugin {
+	t.Helper()
+
+	return MustNew(Options{
+		Name:     "test",
+		Command:  []string{os.Args[0], "-test.run=^TestHelperProcess$"},
+		Env:      []string{helperEnv + "=" + mode},
+		Timeout:  timeout,
+		Priority: 3,
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{
+			name:    "missing name",
+			opts:    Options{Command: []string{"true"}},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "missing command",
+			opts:    Options{Name: "test"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "valid",
+			opts: Options{Name: "test", Command: []string{"true"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDocumentFacts(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		mode       string
+		timeout    time.Duration
+		want       *paperminer.Facts
+		wantErr    bool
+		wantStderr []string
+	}{
+		{
+			name: "facts",
+			mode: "facts",
+			want: &paperminer.Facts{
+				Priority:      3,
+				Title:         ref.Ref("Hello"),
+				Correspondent: ref.Ref("/tmp/doc.pdf"),
+				DocumentType:  ref.Ref("application/pdf"),
+			},
+			wantStderr: []string{"processing /tmp/doc.pdf"},
+		},
+		{name: "null", mode: "null"},
+		{name: "empty", mode: "empty"},
+		{name: "invalid", mode: "invalid", wantErr: true},
+		{name: "fail", mode: "fail", wantErr: true, wantStderr: []string{"failure"}},
+		{name: "timeout", mode: "sleep", timeout: 100 * time.Millisecond, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+
+			got, err := newTestPlugin(t, tc.mode, tc.timeout).DocumentFacts(context.Background(), paperminer.DocumentFacterOptions{
+				Logger: zap.New(core),
+				Info:   &paperminer.DocumentInfo{Title: "Hello"},
+				Path:   "/tmp/doc.pdf",
+				Format: paperminer.FormatPDF,
+			})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DocumentFacts() returned error %v, want error %t", err, tc.wantErr)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Facts diff (-want +got):\n%s", diff)
+			}
+
+			var stderr []string
+
+			for _, entry := range logs.All() {
+				if value, ok := entry.ContextMap()["stderr"].(string); ok {
+					stderr = append(stderr, value)
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantStderr, stderr, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Stderr diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	w := newLogWriter(zap.New(core))
+
+	for _, i := range []string{"first\nsec", "ond\r\n", "", "third", strings.Repeat("x", maxLineLength+1)} {
+		if _, err := w.Write([]byte(i)); err != nil {
+			t.Errorf("Write() failed: %v", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+
+	var got []string
+
+	for _, entry := range logs.All() {
+		got = append(got, entry.ContextMap()["stderr"].(string))
+	}
+
+	want := []string{
+		"first",
+		"second",
+		"third" + strings.Repeat("x", maxLineLength-len("third")),
+		"xxxxxx",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Logged lines diff (-want +got):\n%s", diff)
+	}
+}
@@ -84,7 +84,12 @@ func (tc *TestCase) Assert(t *testing.T, input string) {
 		}
 
 		facts, err := tc.facter.DocumentFacts(ctx, paperminer.DocumentFacterOptions{
-			Logger:   zaptest.NewLogger(t),
+			Logger: zaptest.NewLogger(t),
+			Info: &paperminer.DocumentInfo{
+				OriginalFileName: filepath.Base(input),
+			},
+			Path:     input,
+			Format:   paperminer.FormatPDF,
 			Document: doc,
 		})
 		if err != nil {
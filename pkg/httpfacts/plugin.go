@@ -0,0 +1,392 @@
+// Package httpfacts implements a facter delegating the extraction to an HTTP
+// service. The document file or its text content is sent in a POST request
+// and the response contains the facts in JSON format.
+package httpfacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/staticplug"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTimeout    = time.Minute
+	defaultRetryDelay = time.Second
+)
+
+type Options struct {
+	Name string
+
+	// URL receiving POST requests.
+	URL string
+
+	// Send only the text content of documents as provided by Paperless
+	// instead of the document file. Documents are not downloaded for content
+	// facters.
+	ContentOnly bool
+
+	// Token sent in the "Authorization" header ("Bearer <token>"), if any.
+	BearerToken string
+
+	// Maximum amount of time for a single request. Defaults to one minute.
+	Timeout time.Duration
+
+	// Number of retries after retryable errors, i.e. network errors and
+	// responses with a status of 408, 429 or 5xx.
+	Retries int
+
+	// Delay before the first retry. Doubled for every subsequent retry.
+	// Defaults to one second.
+	RetryDelay time.Duration
+
+	// Priority assigned to reported facts unless set by the service (see
+	// [paperminer.Facts.Priority]).
+	Priority int
+
+	// HTTP client to use. Defaults to [http.DefaultClient].
+	Client *http.Client
+}
+
+// ContentRequest is the JSON request body when only sending the text
+// content.
+type ContentRequest struct {
+	Document *paperminer.DocumentInfo `json:"document"`
+	Content  string                   `json:"content"`
+}
+
+// StatusError is returned for responses with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("HTTP %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+
+	return msg
+}
+
+// Retryable returns whether the request may succeed when retried.
+func (e *StatusError) Retryable() bool {
+	return (e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= 500)
+}
+
+// Permanent returns whether the service rejected the document itself. Other
+// statuses, e.g. for authentication failures or a wrong URL, are likely to be
+// resolved by fixing the configuration.
+func (e *StatusError) Permanent() bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+		http.StatusUnprocessableEntity:
+		return true
+	}
+
+	return false
+}
+
+type Plugin struct {
+	opts Options
+}
+
+var _ staticplug.Plugin = (*Plugin)(nil)
+
+// New creates a facter sending documents to an HTTP service. The service
+// responds with a JSON-encoded [paperminer.Facts] object, "null" or status
+// 204 (No Content) if no facts were found. Status 408, 429 and 5xx are
+// retried. Status 400, 413, 415 and 422 are reported as permanent errors (see
+// [paperminer.ErrPermanent]); other statuses fail only the current attempt.
+//
+// Document files are sent as "multipart/form-data" with a "document" part
+// containing the [paperminer.DocumentInfo] in JSON format, a "format" part
+// with the MIME type of the file and a "file" part.
+// With ContentOnly a JSON-encoded [ContentRequest] is sent.
+func New(opts Options) (*Plugin, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", os.ErrInvalid)
+	}
+
+	if u, err := url.Parse(opts.URL); err != nil {
+		return nil, fmt.Errorf("%w: URL: %w", os.ErrInvalid, err)
+	} else if !(u.Scheme == "http" || u.Scheme == "https") {
+		return nil, fmt.Errorf("%w: URL %q must use HTTP or HTTPS", os.ErrInvalid, opts.URL)
+	}
+
+	if opts.Retries < 0 {
+		return nil, fmt.Errorf("%w: retries must not be negative", os.ErrInvalid)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultRetryDelay
+	}
+
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return &Plugin{
+		opts: opts,
+	}, nil
+}
+
+func MustNew(opts Options) *Plugin {
+	p, err := New(opts)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Plugin) PluginInfo() staticplug.PluginInfo {
+	return staticplug.PluginInfo{
+		Name: p.opts.Name,
+		New: func() (staticplug.Plugin, error) {
+			// Instances are stateless.
+			if p.opts.ContentOnly {
+				return contentFacter{p}, nil
+			}
+
+			return documentFacter{p}, nil
+		},
+	}
+}
+
+type requestBody struct {
+	contentType string
+	data        []byte
+}
+
+func newContentBody(info *paperminer.DocumentInfo, content string) (*requestBody, error) {
+	data, err := json.Marshal(ContentRequest{
+		Document: info,
+		Content:  content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestBody{
+		contentType: "application/json",
+		data:        data,
+	}, nil
+}
+
+func newFileBody(info *paperminer.DocumentInfo, path, format string) (*requestBody, error) {
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	if part, err := mw.CreateFormField("document"); err != nil {
+		return nil, err
+	} else if err := json.NewEncoder(part).Encode(info); err != nil {
+		return nil, err
+	}
+
+	if err := mw.WriteField("format", format); err != nil {
+		return nil, err
+	}
+
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fh.Close()
+
+	part, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(part, fh); err != nil {
+		return nil, err
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &requestBody{
+		contentType: mw.FormDataContentType(),
+		data:        buf.Bytes(),
+	}, nil
+}
+
+// decodeFacts parses a response body. Empty bodies and "null" report no facts.
+func decodeFacts(r io.Reader) (*paperminer.Facts, error) {
+	var facts *paperminer.Facts
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&facts); errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return facts, nil
+}
+
+// send performs a single request. The returned boolean reports whether the
+// error is retryable.
+func (p *Plugin) send(ctx context.Context, body *requestBody) (*paperminer.Facts, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.opts.URL, bytes.NewReader(body.data))
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Header.Set("Content-Type", body.contentType)
+	req.Header.Set("Accept", "application/json")
+
+	if p.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.opts.BearerToken)
+	}
+
+	resp, err := p.opts.Client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		facts, err := decodeFacts(resp.Body)
+		if err != nil {
+			return nil, false, fmt.Errorf("decoding response: %w", err)
+		}
+
+		return facts, false, nil
+
+	case http.StatusNoContent:
+		return nil, false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	statusErr := &StatusError{
+		StatusCode: resp.StatusCode,
+		Body:       string(bytes.TrimSpace(msg)),
+	}
+
+	return nil, statusErr.Retryable(), statusErr
+}
+
+func (p *Plugin) do(ctx context.Context, logger *zap.Logger, body *requestBody) (*paperminer.Facts, error) {
+	delay := p.opts.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		facts, retryable, err := p.send(ctx, body)
+
+		if err == nil {
+			if facts != nil && facts.Priority == 0 {
+				facts.Priority = p.opts.Priority
+			}
+
+			return facts, nil
+		}
+
+		if !retryable {
+			var statusErr *StatusError
+
+			if errors.As(err, &statusErr) && statusErr.Permanent() {
+				// The service rejected the document.
+				err = paperminer.Permanent(err)
+			}
+
+			return nil, err
+		}
+
+		if attempt >= p.opts.Retries {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.Warn("Request failed, retrying",
+			zap.Error(err),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay))
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w (after %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		delay *= 2
+	}
+}
+
+type documentFacter struct {
+	p *Plugin
+}
+
+var _ paperminer.FormatsFacter = documentFacter{}
+
+func (f documentFacter) PluginInfo() staticplug.PluginInfo {
+	return f.p.PluginInfo()
+}
+
+// Formats returns all supported formats. The service is told the format in the
+// request.
+func (f documentFacter) Formats() []string {
+	return []string{paperminer.FormatPDF, paperminer.FormatText, paperminer.FormatMail}
+}
+
+func (f documentFacter) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
+	body, err := newFileBody(opts.Info, opts.Path, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.p.do(ctx, opts.Logger, body)
+}
+
+type contentFacter struct {
+	p *Plugin
+}
+
+var _ paperminer.ContentFacter = contentFacter{}
+
+func (f contentFacter) PluginInfo() staticplug.PluginInfo {
+	return f.p.PluginInfo()
+}
+
+func (f contentFacter) ContentFacts(ctx context.Context, opts paperminer.ContentFacterOptions) (*paperminer.Facts, error) {
+	body, err := newContentBody(opts.Info, opts.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.p.do(ctx, opts.Logger, body)
+}
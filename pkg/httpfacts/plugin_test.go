@@ -0,0 +1,323 @@
+package httpfacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+	"github.com/hansmi/paperminer/internal/testutil"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNew(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{
+			name:    "missing name",
+			opts:    Options{URL: "http://localhost/"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "missing URL",
+			opts:    Options{Name: "test"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "unsupported scheme",
+			opts:    Options{Name: "test", URL: "ftp://localhost/"},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "negative retries",
+			opts:    Options{Name: "test", URL: "http://localhost/", Retries: -1},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "valid",
+			opts: Options{Name: "test", URL: "https://localhost/extract"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPluginInstance(t *testing.T) {
+	for _, contentOnly := range []bool{false, true} {
+		inst, err := MustNew(Options{
+			Name:        "test",
+			URL:         "http://localhost/",
+			ContentOnly: contentOnly,
+		}).PluginInfo().New()
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+
+		if _, ok := inst.(paperminer.ContentFacter); ok != contentOnly {
+			t.Errorf("Instance implements ContentFacter: %t, want %t", ok, contentOnly)
+		}
+
+		if _, ok := inst.(paperminer.DocumentFacter); ok == contentOnly {
+			t.Errorf("Instance implements DocumentFacter: %t, want %t", ok, !contentOnly)
+		}
+	}
+}
+
+func TestDocumentFacts(t *testing.T) {
+	path := testutil.MustWriteFileString(t, filepath.Join(t.TempDir(), "doc.pdf"), "file content")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var info paperminer.DocumentInfo
+
+		if err := json.Unmarshal([]byte(r.FormValue("document")), &info); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fh, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		defer fh.Close()
+
+		content, _ := io.ReadAll(fh)
+
+		json.NewEncoder(w).Encode(paperminer.Facts{
+			Title:         ref.Ref(info.Title + " " + string(content)),
+			Correspondent: ref.Ref(header.Filename),
+			DocumentType:  ref.Ref(r.FormValue("format")),
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	inst, err := MustNew(Options{
+		Name:        "test",
+		URL:         srv.URL,
+		BearerToken: "secret",
+		Priority:    2,
+	}).PluginInfo().New()
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	got, err := inst.(paperminer.DocumentFacter).DocumentFacts(context.Background(), paperminer.DocumentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+		Info:   &paperminer.DocumentInfo{Title: "Hello"},
+		Path:   path,
+		Format: paperminer.FormatPDF,
+	})
+	if err != nil {
+		t.Errorf("DocumentFacts() failed: %v", err)
+	}
+
+	want := &paperminer.Facts{
+		Priority:      2,
+		Title:         ref.Ref("Hello file content"),
+		Correspondent: ref.Ref("doc.pdf"),
+		DocumentType:  ref.Ref("application/pdf"),
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Facts diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestContentFacts(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		responses     []int
+		body          string
+		retries       int
+		want          *paperminer.Facts
+		wantErr       bool
+		wantPermanent bool
+		wantRequests  int32
+	}{
+		{
+			name:         "facts",
+			responses:    []int{http.StatusOK},
+			body:         `{"title": "hello world"}`,
+			want:         &paperminer.Facts{Title: ref.Ref("hello world")},
+			wantRequests: 1,
+		},
+		{
+			name:         "no content",
+			responses:    []int{http.StatusNoContent},
+			wantRequests: 1,
+		},
+		{
+			name:         "null",
+			responses:    []int{http.StatusOK},
+			body:         "null",
+			wantRequests: 1,
+		},
+		{
+			name:         "invalid response",
+			responses:    []int{http.StatusOK},
+			body:         `{"unknown": true}`,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:          "rejected",
+			responses:     []int{http.StatusUnprocessableEntity},
+			retries:       3,
+			wantErr:       true,
+			wantPermanent: true,
+			wantRequests:  1,
+		},
+		{
+			name:         "unauthorized",
+			responses:    []int{http.StatusUnauthorized},
+			retries:      3,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "forbidden",
+			responses:    []int{http.StatusForbidden},
+			retries:      3,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "not found",
+			responses:    []int{http.StatusNotFound},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:          "too large",
+			responses:     []int{http.StatusRequestEntityTooLarge},
+			wantErr:       true,
+			wantPermanent: true,
+			wantRequests:  1,
+		},
+		{
+			name:         "retry",
+			responses:    []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			body:         `{"title": "after retry"}`,
+			retries:      2,
+			want:         &paperminer.Facts{Title: ref.Ref("after retry")},
+			wantRequests: 3,
+		},
+		{
+			name:         "retries exhausted",
+			responses:    []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			retries:      1,
+			wantErr:      true,
+			wantRequests: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req ContentRequest
+
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Content != "hello" {
+					http.Error(w, "bad request", http.StatusBadRequest)
+					return
+				}
+
+				status := tc.responses[requests.Add(1)-1]
+
+				w.WriteHeader(status)
+
+				if status == http.StatusOK {
+					io.WriteString(w, tc.body)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			p := MustNew(Options{
+				Name:        "test",
+				URL:         srv.URL,
+				ContentOnly: true,
+				Retries:     tc.retries,
+				RetryDelay:  time.Millisecond,
+			})
+
+			got, err := contentFacter{p}.ContentFacts(context.Background(), paperminer.ContentFacterOptions{
+				Logger:  zaptest.NewLogger(t),
+				Info:    &paperminer.DocumentInfo{},
+				Content: "hello",
+			})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ContentFacts() returned error %v, want error %t", err, tc.wantErr)
+			}
+
+			if got := errors.Is(err, paperminer.ErrPermanent); got != tc.wantPermanent {
+				t.Errorf("Error %v is permanent: %t, want %t", err, got, tc.wantPermanent)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Facts diff (-want +got):\n%s", diff)
+			}
+
+			if got := requests.Load(); got != tc.wantRequests {
+				t.Errorf("Got %d requests, want %d", got, tc.wantRequests)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-ctx.Done()
+	}))
+	t.Cleanup(func() {
+		// Unblock the handler before shutting down the server.
+		cancel()
+		srv.Close()
+	})
+
+	p := MustNew(Options{
+		Name:        "test",
+		URL:         srv.URL,
+		ContentOnly: true,
+		Timeout:     10 * time.Millisecond,
+	})
+
+	_, err := contentFacter{p}.ContentFacts(context.Background(), paperminer.ContentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ContentFacts() returned %v, want deadline exceeded", err)
+	}
+
+	if errors.Is(err, paperminer.ErrPermanent) {
+		t.Errorf("Timeout is permanent: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package paymentcode parses the payloads of QR codes used for payments, i.e.
+// the Swiss QR-bill and the EPC069-12 standard ("GiroCode").
+package paymentcode
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hansmi/paperminer"
+)
+
+var ErrUnknownFormat = errors.New("unknown payment code format")
+
+// Kinds of payment codes.
+const (
+	KindSwissQRBill = "swiss-qr-bill"
+	KindEPC         = "epc"
+)
+
+// Payment is a payment request decoded from a payment code.
+type Payment struct {
+	Kind string
+
+	// Name of the creditor (payee).
+	Creditor string
+
+	// Account of the creditor, with whitespace removed.
+	IBAN string
+
+	// Requested amount. Nil if the amount is left to the debtor.
+	Amount *paperminer.MonetaryValue
+
+	// Structured payment reference, e.g. a QR or creditor reference.
+	Reference string
+
+	// Unstructured message for the creditor.
+	Message string
+}
+
+// Parse decodes a payment code of any supported kind.
+func Parse(text string) (*Payment, error) {
+	switch {
+	case strings.HasPrefix(text, "SPC"):
+		return ParseSwissQRBill(text)
+	case strings.HasPrefix(text, "BCD"):
+		return ParseEPC(text)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func splitLines(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSpace(line)
+	}
+
+	return lines
+}
+
+func normalizeIBAN(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+func parseAmount(currency, value string) (*paperminer.MonetaryValue, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		return nil, fmt.Errorf("%w: amount %q", os.ErrInvalid, value)
+	}
+
+	return &paperminer.MonetaryValue{
+		Currency: currency,
+		Amount:   amount,
+	}, nil
+}
+
+// ParseSwissQRBill decodes the payload of a Swiss QR-bill (QR type "SPC",
+// version 2.x) as specified by the Swiss Payment Standards.
+func ParseSwissQRBill(text string) (*Payment, error) {
+	// Fields by line, starting at zero.
+	const (
+		fieldType          = 0
+		fieldVersion       = 1
+		fieldIBAN          = 3
+		fieldName          = 5
+		fieldAmount        = 18
+		fieldCurrency      = 19
+		fieldReferenceType = 27
+		fieldReference     = 28
+		fieldMessage       = 29
+		fieldTrailer       = 30
+	)
+
+	lines := splitLines(text)
+
+	if len(lines) <= fieldTrailer || lines[fieldType] != "SPC" {
+		return nil, fmt.Errorf("%w: not a Swiss QR-bill", os.ErrInvalid)
+	}
+
+	if !strings.HasPrefix(lines[fieldVersion], "02") {
+		return nil, fmt.Errorf("%w: unsupported QR-bill version %q", os.ErrInvalid, lines[fieldVersion])
+	}
+
+	if lines[fieldTrailer] != "EPD" {
+		return nil, fmt.Errorf("%w: missing QR-bill trailer", os.ErrInvalid)
+	}
+
+	amount, err := parseAmount(lines[fieldCurrency], lines[fieldAmount])
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Payment{
+		Kind:     KindSwissQRBill,
+		Creditor: lines[fieldName],
+		IBAN:     normalizeIBAN(lines[fieldIBAN]),
+		Amount:   amount,
+		Message:  lines[fieldMessage],
+	}
+
+	if lines[fieldReferenceType] != "NON" {
+		p.Reference = strings.Join(strings.Fields(lines[fieldReference]), "")
+	}
+
+	return p, nil
+}
+
+// ParseEPC decodes the payload of a SEPA credit transfer QR code as specified
+// by the European Payments Council (EPC069-12, versions 001 and 002).
+func ParseEPC(text string) (*Payment, error) {
+	// Fields by line, starting at zero. Fields after the IBAN are optional.
+	const (
+		fieldService   = 0
+		fieldVersion   = 1
+		fieldID        = 3
+		fieldName      = 5
+		fieldIBAN      = 6
+		fieldAmount    = 7
+		fieldReference = 9
+		fieldMessage   = 10
+	)
+
+	lines := splitLines(text)
+
+	if len(lines) <= fieldIBAN || lines[fieldService] != "BCD" {
+		return nil, fmt.Errorf("%w: not an EPC QR code", os.ErrInvalid)
+	}
+
+	if v := lines[fieldVersion]; !(v == "001" || v == "002") {
+		return nil, fmt.Errorf("%w: unsupported EPC QR code version %q", os.ErrInvalid, v)
+	}
+
+	if lines[fieldID] != "SCT" {
+		return nil, fmt.Errorf("%w: unsupported EPC identification %q", os.ErrInvalid, lines[fieldID])
+	}
+
+	field := func(idx int) string {
+		if idx < len(lines) {
+			return lines[idx]
+		}
+
+		return ""
+	}
+
+	var amount *paperminer.MonetaryValue
+
+	if value := field(fieldAmount); value != "" {
+		const currency = "EUR"
+
+		if !strings.HasPrefix(value, currency) {
+			return nil, fmt.Errorf("%w: amount %q not in %s", os.ErrInvalid, value, currency)
+		}
+
+		var err error
+
+		if amount, err = parseAmount(currency, strings.TrimPrefix(value, currency)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Payment{
+		Kind:      KindEPC,
+		Creditor:  lines[fieldName],
+		IBAN:      normalizeIBAN(lines[fieldIBAN]),
+		Amount:    amount,
+		Reference: field(fieldReference),
+		Message:   field(fieldMessage),
+	}, nil
+}
@@ -0,0 +1,123 @@
+package paymentcode
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/paperminer"
+)
+
+func swissQRBill(amount, referenceType, reference string) string {
+	return strings.Join([]string{
+		"SPC", "0200", "1",
+		"CH44 3199 9123 0008 8901 2",
+		"S", "Robert Schneider AG", "Rue du Lac", "1268", "2501", "Biel", "CH",
+		"", "", "", "", "", "", "",
+		amount, "CHF",
+		"S", "Pia-Maria Rutschmann-Schnyder", "Grosse Marktgasse", "28", "9400", "Rorschach", "CH",
+		referenceType, reference,
+		"Order dated 18.06.2020",
+		"EPD",
+	}, "\r\n")
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		want    *Payment
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:    "unknown",
+			input:   "https://example.com/",
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:  "Swiss QR-bill",
+			input: swissQRBill("1949.75", "QRR", "21 00000 00003 13947 14300 09017"),
+			want: &Payment{
+				Kind:      KindSwissQRBill,
+				Creditor:  "Robert Schneider AG",
+				IBAN:      "CH4431999123000889012",
+				Amount:    &paperminer.MonetaryValue{Currency: "CHF", Amount: 1949.75},
+				Reference: "210000000003139471430009017",
+				Message:   "Order dated 18.06.2020",
+			},
+		},
+		{
+			name:  "Swiss QR-bill without amount and reference",
+			input: swissQRBill("", "NON", ""),
+			want: &Payment{
+				Kind:     KindSwissQRBill,
+				Creditor: "Robert Schneider AG",
+				IBAN:     "CH4431999123000889012",
+				Message:  "Order dated 18.06.2020",
+			},
+		},
+		{
+			name:    "Swiss QR-bill with bad amount",
+			input:   swissQRBill("12,50", "NON", ""),
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "Swiss QR-bill with unsupported version",
+			input:   strings.Replace(swissQRBill("", "NON", ""), "0200", "0100", 1),
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "Swiss QR-bill truncated",
+			input:   "SPC\n0200\n1\nCH4431999123000889012\n",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:  "EPC",
+			input: "BCD\n002\n1\nSCT\nBHBLDEHHXXX\nFranz Mustermänn\nDE71 1102 2033 0123 4567 89\nEUR12.3\nGDDS\nRF18539007547034\n\n",
+			want: &Payment{
+				Kind:      KindEPC,
+				Creditor:  "Franz Mustermänn",
+				IBAN:      "DE71110220330123456789",
+				Amount:    &paperminer.MonetaryValue{Currency: "EUR", Amount: 12.3},
+				Reference: "RF18539007547034",
+			},
+		},
+		{
+			name:  "EPC minimal",
+			input: "BCD\n001\n1\nSCT\n\nRed Cross\nBE72000000001616",
+			want: &Payment{
+				Kind:     KindEPC,
+				Creditor: "Red Cross",
+				IBAN:     "BE72000000001616",
+			},
+		},
+		{
+			name:    "EPC with unsupported identification",
+			input:   "BCD\n002\n1\nINST\n\nRed Cross\nBE72000000001616",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "EPC with bad amount",
+			input:   "BCD\n002\n1\nSCT\n\nRed Cross\nBE72000000001616\nCHF10",
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.input)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Payment diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
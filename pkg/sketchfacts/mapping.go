@@ -0,0 +1,136 @@
+package sketchfacts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/factbuilder"
+	"github.com/hansmi/paperminer/internal/tmplargs"
+)
+
+// NodeGroup refers to a named group in the regular expression of a node.
+type NodeGroup struct {
+	Node  string
+	Group string
+}
+
+// DateMapping describes how to parse a date from a node group.
+type DateMapping struct {
+	NodeGroup
+
+	// Layout as accepted by [time.Parse], e.g. "2 January 2006".
+	Layout string
+
+	// Language of month names in the text, e.g. "de" or "fr". Defaults to
+	// English.
+	Locale string
+}
+
+// Mapping declares how to build facts from the nodes of a sketch. Nodes are
+// looked up on all evaluated pages. The first page on which a node is valid
+// is used.
+type Mapping struct {
+	// Go template producing the title. The "group" function returns the text
+	// of a node group ({{ group "node" "group" }}), "valid" reports whether
+	// a node is valid on any page and "trim" removes surrounding whitespace.
+	// Node names must be string constants to be validated. Whitespace is
+	// trimmed from the result and an empty title is not set.
+	Title string
+
+	// Date for the "created" field.
+	Created *DateMapping
+
+	Correspondent string
+	DocumentType  string
+	StoragePath   string
+	SetTags       []string
+}
+
+type compiledMapping struct {
+	m       Mapping
+	builder factbuilder.Builder
+	nodes   []string
+}
+
+func mappingFuncs(report *Report) template.FuncMap {
+	return template.FuncMap{
+		"group": func(node, group string) string {
+			text, _ := report.GroupText(node, group)
+			return text
+		},
+		"valid": func(node string) bool {
+			return report.ValidNode(node) != nil
+		},
+		"trim": strings.TrimSpace,
+	}
+}
+
+func compileMapping(m Mapping) (*compiledMapping, error) {
+	cm := &compiledMapping{
+		m: m,
+		builder: factbuilder.Builder{
+			Correspondent: m.Correspondent,
+			DocumentType:  m.DocumentType,
+			StoragePath:   m.StoragePath,
+			SetTags:       m.SetTags,
+		},
+	}
+
+	if m.Title != "" {
+		tmpl, err := template.New("title").
+			Option("missingkey=error").
+			Funcs(mappingFuncs(nil)).
+			Parse(m.Title)
+		if err != nil {
+			return nil, fmt.Errorf("title template: %w", err)
+		}
+
+		cm.builder.Title = tmpl
+		cm.nodes = append(cm.nodes, tmplargs.FirstStrings(tmpl, "group", "valid")...)
+	}
+
+	if m.Created != nil {
+		if err := factbuilder.ValidateDate(m.Created.Layout, m.Created.Locale); err != nil {
+			return nil, err
+		}
+
+		cm.builder.CreatedLayout = m.Created.Layout
+		cm.builder.CreatedLocale = m.Created.Locale
+		cm.nodes = append(cm.nodes, m.Created.Node)
+	}
+
+	slices.Sort(cm.nodes)
+	cm.nodes = slices.Compact(cm.nodes)
+
+	return cm, nil
+}
+
+// GroupText returns the text of a named group of the first valid node with the
+// given name, without leading and trailing whitespace. False is returned if
+// the node isn't valid on any evaluated page or the group doesn't exist.
+func (r *Report) GroupText(node, group string) (string, bool) {
+	nr := r.ValidNode(node)
+	if nr == nil {
+		return "", false
+	}
+
+	match, ok := nr.TextMatch().Named(group)
+	if !ok {
+		return "", false
+	}
+
+	return strings.TrimSpace(match.Text), true
+}
+
+func (cm *compiledMapping) build(report *Report) (*paperminer.Facts, error) {
+	var created string
+
+	if dm := cm.m.Created; dm != nil {
+		created, _ = report.GroupText(dm.Node, dm.Group)
+	}
+
+	return cm.builder.Build(mappingFuncs(report), created)
+}
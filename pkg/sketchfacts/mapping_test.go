@@ -0,0 +1,173 @@
+package sketchfacts
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/dossier/pkg/sketch"
+	"github.com/hansmi/paperminer"
+	"github.com/hansmi/paperminer/internal/ref"
+)
+
+func TestCompileMapping(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		m         Mapping
+		wantErr   error
+		wantNodes []string
+	}{
+		{name: "empty"},
+		{
+			name: "title nodes",
+			m: Mapping{
+				Title: `Invoice {{ group "value" "number" }}{{ if valid "sender" }} from ACME{{ end }}{{ with group "value" "x" | trim }}{{ . }}{{ end }}`,
+			},
+			wantNodes: []string{"sender", "value"},
+		},
+		{
+			name: "created",
+			m: Mapping{
+				Title: `{{ group "label" "a" }}`,
+				Created: &DateMapping{
+					NodeGroup: NodeGroup{Node: "value", Group: "date"},
+					Layout:    "2.1.2006",
+				},
+			},
+			wantNodes: []string{"label", "value"},
+		},
+		{
+			name: "created without layout",
+			m: Mapping{
+				Created: &DateMapping{
+					NodeGroup: NodeGroup{Node: "value", Group: "date"},
+				},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unsupported locale",
+			m: Mapping{
+				Created: &DateMapping{
+					NodeGroup: NodeGroup{Node: "value", Group: "date"},
+					Layout:    "2 January 2006",
+					Locale:    "xx",
+				},
+			},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := compileMapping(tc.m)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if err == nil {
+				if diff := cmp.Diff(tc.wantNodes, got.nodes, cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("Nodes diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileMappingInvalidTemplate(t *testing.T) {
+	if _, err := compileMapping(Mapping{Title: "{{ unknown }}"}); err == nil {
+		t.Errorf("compileMapping() succeeded with invalid template")
+	}
+}
+
+func TestMappingBuild(t *testing.T) {
+	cm, err := compileMapping(Mapping{
+		Title: `{{ if valid "value" }}Invoice {{ group "value" "value" }}{{ end }}`,
+		Created: &DateMapping{
+			NodeGroup: NodeGroup{Node: "value", Group: "date"},
+			Layout:    "2006-01-02",
+		},
+		Correspondent: "ACME",
+		DocumentType:  "Invoice",
+		SetTags:       []string{"tag1", "tag2"},
+	})
+	if err != nil {
+		t.Fatalf("compileMapping() failed: %v", err)
+	}
+
+	got, err := cm.build(&Report{
+		Pages: []PageReport{
+			{PageReport: &sketch.PageReport{}, Number: 1},
+		},
+	})
+	if err != nil {
+		t.Errorf("build() failed: %v", err)
+	}
+
+	want := &paperminer.Facts{
+		Correspondent: ref.Ref("ACME"),
+		DocumentType:  ref.Ref("Invoice"),
+		SetTags:       []string{"tag1", "tag2"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Facts diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewMapping(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{
+			name: "all nodes used",
+			opts: Options{
+				Required: []string{"sender"},
+				Mapping: &Mapping{
+					Title: `{{ group "value" "value" }}`,
+				},
+			},
+		},
+		{
+			name: "unknown node",
+			opts: Options{
+				Mapping: &Mapping{
+					Title: `{{ group "vlaue" "value" }}`,
+				},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unused node",
+			opts: Options{
+				Mapping: &Mapping{
+					Title: `{{ group "value" "value" }}`,
+				},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "with build function",
+			opts: Options{
+				Mapping: &Mapping{},
+				Build: func(*sketch.PageReport) (*paperminer.Facts, error) {
+					return nil, nil
+				},
+			},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Name = "test"
+			tc.opts.Textproto = testSketchTextproto
+
+			_, err := New(tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
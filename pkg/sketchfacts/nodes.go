@@ -0,0 +1,97 @@
+package sketchfacts
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/hansmi/dossier/proto/sketchpb"
+	"go.uber.org/multierr"
+)
+
+// sketchNodes describes the nodes declared by a sketch.
+type sketchNodes struct {
+	declared []string
+
+	// Nodes used as a reference for the position of other nodes.
+	referenced map[string]struct{}
+}
+
+func newSketchNodes(pb *sketchpb.Sketch) *sketchNodes {
+	result := &sketchNodes{
+		referenced: map[string]struct{}{},
+	}
+
+	for _, node := range pb.GetNodes() {
+		result.declared = append(result.declared, node.GetName())
+
+		for _, area := range node.GetSearchAreas() {
+			for _, pos := range []*sketchpb.Position{area.GetTopLeft(), area.GetBottomRight()} {
+				if name := pos.GetRel().GetNode(); name != "" {
+					result.referenced[name] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// checkKnown returns an error for every node name not declared in the sketch.
+func (n *sketchNodes) checkKnown(kind string, names []string) error {
+	var err error
+
+	for _, name := range names {
+		if !slices.Contains(n.declared, name) {
+			multierr.AppendInto(&err, fmt.Errorf("%w: %s node %q not declared in sketch", os.ErrInvalid, kind, name))
+		}
+	}
+
+	return err
+}
+
+// checkUsed returns an error for every declared node which is neither among
+// the given names nor referenced by another node.
+func (n *sketchNodes) checkUsed(used map[string]struct{}) error {
+	var err error
+
+	for _, name := range n.declared {
+		_, isUsed := used[name]
+		_, isReferenced := n.referenced[name]
+
+		if !(isUsed || isReferenced) {
+			multierr.AppendInto(&err, fmt.Errorf("%w: node %q is not used", os.ErrInvalid, name))
+		}
+	}
+
+	return err
+}
+
+// validateNodes checks the node names in the options against the nodes
+// declared in the sketch. Unused nodes are only detected when all node names
+// used by the build function are known.
+func validateNodes(pb *sketchpb.Sketch, opts Options) error {
+	nodes := newSketchNodes(pb)
+	requiredOnPage := slices.Sorted(maps.Keys(opts.RequiredOnPage))
+
+	err := multierr.Combine(
+		nodes.checkKnown("required", opts.Required),
+		nodes.checkKnown("required", requiredOnPage),
+		nodes.checkKnown("build", opts.BuildNodes),
+	)
+
+	if err == nil && opts.BuildNodes != nil {
+		used := map[string]struct{}{}
+
+		for _, names := range [][]string{opts.Required, requiredOnPage, opts.BuildNodes} {
+			for _, name := range names {
+				used[name] = struct{}{}
+			}
+		}
+
+		err = nodes.checkUsed(used)
+	}
+
+	return err
+}
@@ -0,0 +1,204 @@
+package sketchfacts
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/dossier/pkg/sketch"
+	"github.com/hansmi/paperminer"
+)
+
+const testSketchTextproto = `
+nodes {
+  name: "label"
+  search_areas {
+    top_left { abs { left { cm: 1 } top { cm: 1 } } }
+    width { cm: 10 }
+    height { cm: 10 }
+  }
+  line_text { regex: "(?i)total" }
+}
+
+nodes {
+  name: "value"
+  search_areas {
+    top_left { rel { node: "label" feature: TOP_LEFT } }
+    width { cm: 5 }
+    height { cm: 1 }
+  }
+  line_text { regex: "(?P<value>\\d+)" }
+}
+
+nodes {
+  name: "sender"
+  search_areas {
+    top_left { abs { left { cm: 1 } top { cm: 1 } } }
+    width { cm: 10 }
+    height { cm: 2 }
+  }
+  line_text { regex: "(?i)acme" }
+}
+`
+
+func TestNewNodeValidation(t *testing.T) {
+	build := func(*sketch.PageReport) (*paperminer.Facts, error) {
+		return nil, nil
+	}
+
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{
+			name: "no node names",
+		},
+		{
+			name: "required",
+			opts: Options{
+				Required: []string{"value", "sender"},
+			},
+		},
+		{
+			name: "unknown required",
+			opts: Options{
+				Required: []string{"value", "sendr"},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unknown required on page",
+			opts: Options{
+				RequiredOnPage: map[string]int{"lable": 1},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "required on evaluated page",
+			opts: Options{
+				Pages:          AllPages,
+				RequiredOnPage: map[string]int{"label": 2, "value": -1},
+			},
+		},
+		{
+			name: "required on page never evaluated",
+			opts: Options{
+				RequiredOnPage: map[string]int{"label": 2},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "required on last page without selection",
+			opts: Options{
+				Pages:          PageRange(1, 3),
+				RequiredOnPage: map[string]int{"label": -1},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "unknown build node",
+			opts: Options{
+				BuildNodes: []string{"missing"},
+			},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "all nodes used",
+			opts: Options{
+				Required:   []string{"sender"},
+				BuildNodes: []string{"value"},
+			},
+		},
+		{
+			name: "referenced node is used",
+			opts: Options{
+				RequiredOnPage: map[string]int{"value": 1},
+				BuildNodes:     []string{"sender"},
+			},
+		},
+		{
+			name: "unused node",
+			opts: Options{
+				Required:   []string{"sender"},
+				BuildNodes: []string{},
+			},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Name = "test"
+			tc.opts.Textproto = testSketchTextproto
+			tc.opts.Build = build
+
+			_, err := New(tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewInvalidTextproto(t *testing.T) {
+	if _, err := New(Options{
+		Name:      "test",
+		Textproto: "nodes { unknown_field: 1 }",
+		Build: func(*sketch.PageReport) (*paperminer.Facts, error) {
+			return nil, nil
+		},
+	}); err == nil {
+		t.Errorf("New() succeeded with invalid sketch")
+	}
+}
+
+func TestNewBuilders(t *testing.T) {
+	build := func(*sketch.PageReport) (*paperminer.Facts, error) {
+		return nil, nil
+	}
+
+	buildPages := func(*Report) (*paperminer.Facts, error) {
+		return nil, nil
+	}
+
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{
+			name:    "none",
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name: "build",
+			opts: Options{Build: build},
+		},
+		{
+			name: "mapping",
+			opts: Options{Mapping: &Mapping{Correspondent: "ACME"}},
+		},
+		{
+			name:    "build and build pages",
+			opts:    Options{Build: build, BuildPages: buildPages},
+			wantErr: os.ErrInvalid,
+		},
+		{
+			name:    "build pages and mapping",
+			opts:    Options{BuildPages: buildPages, Mapping: &Mapping{}},
+			wantErr: os.ErrInvalid,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Name = "test"
+			tc.opts.Textproto = testSketchTextproto
+
+			_, err := New(tc.opts)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
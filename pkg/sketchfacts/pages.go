@@ -0,0 +1,218 @@
+package sketchfacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hansmi/dossier/pkg/sketch"
+	"github.com/hansmi/dossier/pkg/sketchiter"
+)
+
+// Pages selects the pages of a document to evaluate. Page numbers start at 1.
+// Negative numbers count from the end of the document, i.e. -1 is the last
+// page. The zero value selects the first page.
+type Pages struct {
+	First int
+
+	// Last page to evaluate (inclusive). Zero selects the same page as First.
+	Last int
+}
+
+var (
+	FirstPage = Pages{First: 1, Last: 1}
+	LastPage  = Pages{First: -1, Last: -1}
+	AllPages  = Pages{First: 1, Last: -1}
+)
+
+var pagesByName = map[string]Pages{
+	"":      FirstPage,
+	"first": FirstPage,
+	"last":  LastPage,
+	"all":   AllPages,
+}
+
+// ParsePages returns the selection for a name, i.e. "first" (the default if
+// empty), "last" or "all".
+func ParsePages(name string) (Pages, error) {
+	pages, ok := pagesByName[name]
+	if !ok {
+		return Pages{}, fmt.Errorf("%w: unknown pages %q", os.ErrInvalid, name)
+	}
+
+	return pages, nil
+}
+
+// PageRange selects the pages from first to last (inclusive).
+func PageRange(first, last int) Pages {
+	return Pages{First: first, Last: last}
+}
+
+func (p Pages) normalize() Pages {
+	if p.First == 0 {
+		p.First = 1
+	}
+
+	if p.Last == 0 {
+		p.Last = p.First
+	}
+
+	return p
+}
+
+func (p Pages) validate() error {
+	if p.First == 0 && p.Last != 0 {
+		return fmt.Errorf("%w: first page must be set together with last page", os.ErrInvalid)
+	}
+
+	p = p.normalize()
+
+	if (p.First > 0) == (p.Last > 0) && p.First > p.Last {
+		return fmt.Errorf("%w: first page %d is after last page %d", os.ErrInvalid, p.First, p.Last)
+	}
+
+	return nil
+}
+
+// mayContain returns whether a page number can be among the selected pages
+// for some number of pages. Negative numbers can only be resolved when the
+// selection is relative to the end of the document.
+func (p Pages) mayContain(num int) bool {
+	p = p.normalize()
+
+	switch {
+	case num > 0:
+		return !(p.First > 0 && num < p.First) && !(p.Last > 0 && num > p.Last)
+	case num < 0:
+		return p.fromEnd() && !(p.First < 0 && num < p.First) && !(p.Last < 0 && num > p.Last)
+	}
+
+	return false
+}
+
+// fromEnd returns whether the total number of pages is needed to resolve the
+// selection.
+func (p Pages) fromEnd() bool {
+	p = p.normalize()
+
+	return p.First < 0 || p.Last < 0
+}
+
+// resolvePage converts a page number to a positive number given the total
+// number of pages.
+func resolvePage(num, count int) int {
+	if num < 0 {
+		return count + 1 + num
+	}
+
+	return num
+}
+
+// contains returns whether a page is selected given the total number of
+// pages.
+func (p Pages) contains(num, count int) bool {
+	p = p.normalize()
+
+	return num >= max(1, resolvePage(p.First, count)) &&
+		num <= min(count, resolvePage(p.Last, count))
+}
+
+// PageReport is the sketch report for a single page.
+type PageReport struct {
+	*sketch.PageReport
+
+	// Page number starting at 1.
+	Number int
+}
+
+// Report contains the sketch reports of all evaluated pages in page order.
+type Report struct {
+	Pages []PageReport
+
+	// Total number of pages in the document. Zero if unknown because not all
+	// pages were evaluated.
+	PageCount int
+}
+
+// Page returns the report for a page number. Negative numbers count from the
+// end of the document and require the total number of pages to be known. Nil
+// is returned if the page wasn't evaluated.
+func (r *Report) Page(num int) *PageReport {
+	if num < 0 {
+		if r.PageCount == 0 {
+			return nil
+		}
+
+		num = resolvePage(num, r.PageCount)
+	}
+
+	for idx := range r.Pages {
+		if r.Pages[idx].Number == num {
+			return &r.Pages[idx]
+		}
+	}
+
+	return nil
+}
+
+// ValidNode returns the first valid node with the given name across all
+// evaluated pages. Nil is returned if the node isn't valid on any page.
+func (r *Report) ValidNode(name string) *sketch.NodeReport {
+	for _, page := range r.Pages {
+		if node := page.NodeByName(name); node != nil && node.Valid() {
+			return node
+		}
+	}
+
+	return nil
+}
+
+type pageIter interface {
+	Next(context.Context) (*sketch.PageReport, error)
+}
+
+var _ pageIter = (*sketchiter.PageIter)(nil)
+
+// collectPages evaluates the selected pages. All pages are evaluated when the
+// selection is relative to the end of the document.
+func collectPages(ctx context.Context, it pageIter, pages Pages) (*Report, error) {
+	var all []PageReport
+
+	complete := false
+	pages = pages.normalize()
+	fromEnd := pages.fromEnd()
+
+	for num := 1; fromEnd || num <= pages.Last; num++ {
+		report, err := it.Next(ctx)
+
+		if errors.Is(err, sketchiter.Done) {
+			complete = true
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("page %d: %w", num, err)
+		}
+
+		all = append(all, PageReport{
+			PageReport: report,
+			Number:     num,
+		})
+	}
+
+	// Number of pages seen; only the total if all pages were evaluated.
+	count := len(all)
+
+	result := &Report{}
+
+	if complete {
+		result.PageCount = count
+	}
+
+	for _, page := range all {
+		if pages.contains(page.Number, count) {
+			result.Pages = append(result.Pages, page)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package sketchfacts
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hansmi/dossier/pkg/sketch"
+	"github.com/hansmi/dossier/pkg/sketchiter"
+)
+
+type fakePageIter struct {
+	remaining int
+	err       error
+	calls     int
+}
+
+func (it *fakePageIter) Next(context.Context) (*sketch.PageReport, error) {
+	it.calls++
+
+	if it.remaining <= 0 {
+		if it.err != nil {
+			return nil, it.err
+		}
+
+		return nil, sketchiter.Done
+	}
+
+	it.remaining--
+
+	return &sketch.PageReport{}, nil
+}
+
+func TestPagesValidate(t *testing.T) {
+	for _, tc := range []struct {
+		pages   Pages
+		wantErr error
+	}{
+		{pages: Pages{}},
+		{pages: FirstPage},
+		{pages: LastPage},
+		{pages: AllPages},
+		{pages: PageRange(2, 3)},
+		{pages: PageRange(-3, -1)},
+		{pages: PageRange(2, -2)},
+		{pages: Pages{Last: 1}, wantErr: os.ErrInvalid},
+		{pages: PageRange(3, 2), wantErr: os.ErrInvalid},
+		{pages: PageRange(-1, -2), wantErr: os.ErrInvalid},
+	} {
+		err := tc.pages.validate()
+
+		if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("validate(%+v) error diff (-want +got):\n%s", tc.pages, diff)
+		}
+	}
+}
+
+func TestParsePages(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		want    Pages
+		wantErr error
+	}{
+		{want: FirstPage},
+		{name: "first", want: FirstPage},
+		{name: "last", want: LastPage},
+		{name: "all", want: AllPages},
+		{name: "second", wantErr: os.ErrInvalid},
+	} {
+		got, err := ParsePages(tc.name)
+
+		if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+			t.Errorf("ParsePages(%q) error diff (-want +got):\n%s", tc.name, diff)
+		}
+
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParsePages(%q) diff (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestPagesMayContain(t *testing.T) {
+	for _, tc := range []struct {
+		pages Pages
+		num   int
+		want  bool
+	}{
+		{pages: FirstPage, num: 1, want: true},
+		{pages: FirstPage, num: 2},
+		{pages: FirstPage, num: -1},
+		{pages: FirstPage, num: 0},
+		{pages: Pages{}, num: 1, want: true},
+		{pages: LastPage, num: 1, want: true},
+		{pages: LastPage, num: 5, want: true},
+		{pages: LastPage, num: -1, want: true},
+		{pages: LastPage, num: -2},
+		{pages: AllPages, num: 3, want: true},
+		{pages: AllPages, num: -3, want: true},
+		{pages: PageRange(2, 3), num: 1},
+		{pages: PageRange(2, 3), num: 3, want: true},
+		{pages: PageRange(2, 3), num: 4},
+		{pages: PageRange(-3, -2), num: -1},
+		{pages: PageRange(-3, -2), num: -4},
+		{pages: PageRange(-3, -2), num: -2, want: true},
+		{pages: PageRange(-3, -2), num: 1, want: true},
+		{pages: PageRange(2, -2), num: 1},
+		{pages: PageRange(2, -2), num: -1},
+		{pages: PageRange(2, -2), num: -2, want: true},
+	} {
+		if got := tc.pages.mayContain(tc.num); got != tc.want {
+			t.Errorf("%+v.mayContain(%d) = %v, want %v", tc.pages, tc.num, got, tc.want)
+		}
+	}
+}
+
+func TestCollectPages(t *testing.T) {
+	errTest := errors.New("test error")
+
+	for _, tc := range []struct {
+		name          string
+		count         int
+		err           error
+		pages         Pages
+		wantErr       error
+		wantNumbers   []int
+		wantPageCount int
+		wantCalls     int
+	}{
+		{
+			name:          "empty document",
+			wantPageCount: 0,
+			wantCalls:     1,
+		},
+		{
+			name:        "default",
+			count:       3,
+			wantNumbers: []int{1},
+			wantCalls:   1,
+		},
+		{
+			name:          "last",
+			count:         3,
+			pages:         LastPage,
+			wantNumbers:   []int{3},
+			wantPageCount: 3,
+			wantCalls:     4,
+		},
+		{
+			name:          "all",
+			count:         3,
+			pages:         AllPages,
+			wantNumbers:   []int{1, 2, 3},
+			wantPageCount: 3,
+			wantCalls:     4,
+		},
+		{
+			name:        "range",
+			count:       5,
+			pages:       PageRange(2, 3),
+			wantNumbers: []int{2, 3},
+			wantCalls:   3,
+		},
+		{
+			name:          "range beyond end",
+			count:         2,
+			pages:         PageRange(2, 4),
+			wantNumbers:   []int{2},
+			wantPageCount: 2,
+			wantCalls:     3,
+		},
+		{
+			name:          "range from end",
+			count:         5,
+			pages:         PageRange(2, -2),
+			wantNumbers:   []int{2, 3, 4},
+			wantPageCount: 5,
+			wantCalls:     6,
+		},
+		{
+			name:      "error",
+			count:     1,
+			err:       errTest,
+			pages:     AllPages,
+			wantErr:   errTest,
+			wantCalls: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			it := &fakePageIter{
+				remaining: tc.count,
+				err:       tc.err,
+			}
+
+			got, err := collectPages(context.Background(), it, tc.pages)
+
+			if diff := cmp.Diff(tc.wantErr, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("Error diff (-want +got):\n%s", diff)
+			}
+
+			if it.calls != tc.wantCalls {
+				t.Errorf("Next() called %d times, want %d", it.calls, tc.wantCalls)
+			}
+
+			if err != nil {
+				return
+			}
+
+			var numbers []int
+
+			for _, page := range got.Pages {
+				numbers = append(numbers, page.Number)
+			}
+
+			if diff := cmp.Diff(tc.wantNumbers, numbers, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Page numbers diff (-want +got):\n%s", diff)
+			}
+
+			if got.PageCount != tc.wantPageCount {
+				t.Errorf("Got page count %d, want %d", got.PageCount, tc.wantPageCount)
+			}
+		})
+	}
+}
+
+func TestReportPage(t *testing.T) {
+	r := &Report{
+		Pages: []PageReport{
+			{Number: 2},
+			{Number: 3},
+		},
+	}
+
+	for _, tc := range []struct {
+		pageCount int
+		num       int
+		want      int
+	}{
+		{num: 1},
+		{num: 2, want: 2},
+		{num: 3, want: 3},
+		{num: -1},
+		{pageCount: 3, num: -1, want: 3},
+		{pageCount: 3, num: -2, want: 2},
+		{pageCount: 3, num: -3},
+	} {
+		r.PageCount = tc.pageCount
+
+		got := 0
+
+		if page := r.Page(tc.num); page != nil {
+			got = page.Number
+		}
+
+		if got != tc.want {
+			t.Errorf("Page(%d) with %d pages returned page %d, want %d", tc.num, tc.pageCount, got, tc.want)
+		}
+	}
+}
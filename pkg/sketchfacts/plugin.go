@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 
 	"github.com/hansmi/dossier/pkg/sketch"
 	"github.com/hansmi/dossier/pkg/sketchiter"
@@ -12,12 +14,17 @@ import (
 	"github.com/hansmi/paperminer"
 	"github.com/hansmi/staticplug"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/prototext"
 )
 
 var errInvalidReport = errors.New("invalid report")
 
 type BuildFunc func(*sketch.PageReport) (*paperminer.Facts, error)
 
+type BuildPagesFunc func(*Report) (*paperminer.Facts, error)
+
+type BuildDocumentFunc func(context.Context, paperminer.DocumentFacterOptions, *Report) (*paperminer.Facts, error)
+
 type Options struct {
 	Name string
 
@@ -27,53 +34,120 @@ type Options struct {
 	// Sketch definition as a protocol buffer message.
 	Sketch *sketchpb.Sketch
 
-	// Nodes which must be valid on the first page.
+	// Pages to evaluate. Defaults to the first page.
+	Pages Pages
+
+	// Nodes which must be valid on at least one evaluated page.
 	Required []string
 
-	// Function to build facts from report. Return nil facts to indicate that
-	// a document wasn't recognized.
+	// Nodes which must be valid on a particular page, keyed by node name. See
+	// [Pages] for page numbers. Pages which are never evaluated are rejected.
+	RequiredOnPage map[string]int
+
+	// Nodes read by the build function. Setting this field, even to an empty
+	// list, enables checking for nodes declared in the sketch and never used.
+	BuildNodes []string
+
+	// Function to build facts from the report of the first evaluated page.
+	// Return nil facts to indicate that a document wasn't recognized.
 	Build BuildFunc
+
+	// Function to build facts from the reports of all evaluated pages. Return
+	// nil facts to indicate that a document wasn't recognized. Mutually
+	// exclusive with Build.
+	BuildPages BuildPagesFunc
+
+	// Like BuildPages, but also receiving the context and options passed to
+	// the facter, e.g. for logging. Mutually exclusive with Build and
+	// BuildPages.
+	BuildDocument BuildDocumentFunc
+
+	// Declarative mapping from nodes to facts. Mutually exclusive with the
+	// build functions. Nodes used by the mapping are checked against the
+	// sketch and unused nodes are reported (see BuildNodes).
+	Mapping *Mapping
+
+	// Priority assigned to built facts unless set by the build function (see
+	// [paperminer.Facts.Priority]).
+	Priority int
 }
 
 type Plugin struct {
-	opts Options
-	s    *sketch.Sketch
+	opts    Options
+	s       *sketch.Sketch
+	mapping *compiledMapping
 }
 
 var _ staticplug.Plugin = (*Plugin)(nil)
 var _ paperminer.DocumentFacter = (*Plugin)(nil)
 
-// New creates a facter using a dossier sketch to evaluate the first page of
-// a document. Pages beyond the first are ignored.
+// New creates a facter using a dossier sketch to evaluate the selected pages
+// of a document (the first page by default).
 func New(opts Options) (*Plugin, error) {
-	var err error
-	var s *sketch.Sketch
+	pb := opts.Sketch
 
 	if opts.Textproto != "" && opts.Sketch == nil {
-		s, err = sketch.CompileFromTextprotoString(opts.Textproto)
-	} else if opts.Textproto == "" && opts.Sketch != nil {
-		s, err = sketch.Compile(opts.Sketch)
-	} else {
+		pb = &sketchpb.Sketch{}
+
+		if err := prototext.Unmarshal([]byte(opts.Textproto), pb); err != nil {
+			return nil, fmt.Errorf("parsing sketch: %w", err)
+		}
+	} else if !(opts.Textproto == "" && opts.Sketch != nil) {
 		return nil, fmt.Errorf(`%w: exactly one of "Textproto" and "Sketch" may be set`, os.ErrInvalid)
 	}
 
+	s, err := sketch.Compile(pb)
 	if err != nil {
 		return nil, err
 	}
 
-	if opts.Build == nil {
-		return nil, fmt.Errorf("%w: build function is required", os.ErrInvalid)
+	var mapping *compiledMapping
+
+	if countSet(opts.Build != nil, opts.BuildPages != nil, opts.BuildDocument != nil, opts.Mapping != nil) != 1 {
+		return nil, fmt.Errorf(`%w: exactly one of "Build", "BuildPages", "BuildDocument" and "Mapping" must be set`, os.ErrInvalid)
 	}
 
-	// TODO: Check sketch for the required nodes. For that to be possible the
-	// sketch needs to expose the information without analyzing.
+	if opts.Mapping != nil {
+		if mapping, err = compileMapping(*opts.Mapping); err != nil {
+			return nil, fmt.Errorf("sketch %q mapping: %w", opts.Name, err)
+		}
+
+		opts.BuildNodes = slices.Concat([]string{}, opts.BuildNodes, mapping.nodes)
+	}
+
+	if err := opts.Pages.validate(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(opts.RequiredOnPage)) {
+		if num := opts.RequiredOnPage[name]; !opts.Pages.mayContain(num) {
+			return nil, fmt.Errorf("%w: sketch %q: node %q is required on page %d which is never evaluated", os.ErrInvalid, opts.Name, name, num)
+		}
+	}
+
+	if err := validateNodes(pb, opts); err != nil {
+		return nil, fmt.Errorf("sketch %q: %w", opts.Name, err)
+	}
 
 	return &Plugin{
-		opts: opts,
-		s:    s,
+		opts:    opts,
+		s:       s,
+		mapping: mapping,
 	}, nil
 }
 
+func countSet(values ...bool) int {
+	count := 0
+
+	for _, i := range values {
+		if i {
+			count++
+		}
+	}
+
+	return count
+}
+
 func MustNew(opts Options) *Plugin {
 	p, err := New(opts)
 	if err != nil {
@@ -92,12 +166,39 @@ func (p *Plugin) PluginInfo() staticplug.PluginInfo {
 	}
 }
 
-func (p *Plugin) validate(logger *zap.Logger, report *sketch.PageReport) (bool, error) {
+func (p *Plugin) validate(logger *zap.Logger, report *Report) (bool, error) {
+	if len(report.Pages) == 0 {
+		logger.Debug("No pages evaluated")
+		return false, nil
+	}
+
+	for _, page := range report.Pages {
+		for _, name := range p.opts.Required {
+			if page.NodeByName(name) == nil {
+				return false, fmt.Errorf("%w: node %q not found on page %d", errInvalidReport, name, page.Number)
+			}
+		}
+	}
+
 	for _, name := range p.opts.Required {
-		if node := report.NodeByName(name); node == nil {
-			return false, fmt.Errorf("%w: node %q not found", errInvalidReport, name)
+		if report.ValidNode(name) == nil {
+			logger.Debug("Node not valid on any evaluated page", zap.String("node", name))
+			return false, nil
+		}
+	}
+
+	for name, num := range p.opts.RequiredOnPage {
+		page := report.Page(num)
+
+		if page == nil {
+			logger.Debug("Page for required node not evaluated", zap.String("node", name), zap.Int("page", num))
+			return false, nil
+		}
+
+		if node := page.NodeByName(name); node == nil {
+			return false, fmt.Errorf("%w: node %q not found on page %d", errInvalidReport, name, page.Number)
 		} else if !node.Valid() {
-			logger.Debug("Node not valid on first page", zap.String("node", name))
+			logger.Debug("Node not valid on page", zap.String("node", name), zap.Int("page", page.Number))
 			return false, nil
 		}
 	}
@@ -106,14 +207,13 @@ func (p *Plugin) validate(logger *zap.Logger, report *sketch.PageReport) (bool,
 }
 
 func (p *Plugin) DocumentFacts(ctx context.Context, opts paperminer.DocumentFacterOptions) (*paperminer.Facts, error) {
-	it := sketchiter.NewPageIter(p.s, opts.Document)
-
-	report, err := it.Next(ctx)
-
-	if errors.Is(err, sketchiter.Done) {
-		// Empty document
+	if opts.Document == nil {
+		// Sketches are only evaluated on PDF documents.
 		return nil, nil
-	} else if err != nil {
+	}
+
+	report, err := collectPages(ctx, sketchiter.NewPageIter(p.s, opts.Document), p.opts.Pages)
+	if err != nil {
 		return nil, err
 	}
 
@@ -121,10 +221,25 @@ func (p *Plugin) DocumentFacts(ctx context.Context, opts paperminer.DocumentFact
 		return nil, err
 	}
 
-	facts, err := p.opts.Build(report)
+	var facts *paperminer.Facts
+
+	if p.mapping != nil {
+		facts, err = p.mapping.build(report)
+	} else if p.opts.BuildPages != nil {
+		facts, err = p.opts.BuildPages(report)
+	} else if p.opts.BuildDocument != nil {
+		facts, err = p.opts.BuildDocument(ctx, opts, report)
+	} else {
+		facts, err = p.opts.Build(report.Pages[0].PageReport)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("building facts: %w", err)
 	}
 
+	if facts != nil && facts.Priority == 0 {
+		facts.Priority = p.opts.Priority
+	}
+
 	return facts, nil
 }
@@ -0,0 +1,33 @@
+package sketchfacts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hansmi/paperminer"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDocumentFactsOtherFormat(t *testing.T) {
+	p := MustNew(Options{
+		Name:      "test",
+		Textproto: testSketchTextproto,
+		BuildPages: func(*Report) (*paperminer.Facts, error) {
+			t.Errorf("Build function called")
+			return nil, nil
+		},
+	})
+
+	got, err := p.DocumentFacts(context.Background(), paperminer.DocumentFacterOptions{
+		Logger: zaptest.NewLogger(t),
+		Info:   &paperminer.DocumentInfo{},
+		Format: paperminer.FormatMail,
+	})
+	if err != nil {
+		t.Errorf("DocumentFacts() failed: %v", err)
+	}
+
+	if got != nil {
+		t.Errorf("DocumentFacts() returned %v, want nil", got)
+	}
+}
@@ -2,22 +2,125 @@ package paperminer
 
 import (
 	"context"
+	"net/mail"
+	"time"
 
 	"github.com/hansmi/dossier"
 	"github.com/hansmi/staticplug"
 	"go.uber.org/zap"
 )
 
+// Formats of document files as MIME types.
+const (
+	FormatPDF  = "application/pdf"
+	FormatText = "text/plain"
+	FormatMail = "message/rfc822"
+)
+
 type DocumentFacterOptions struct {
-	Logger   *zap.Logger
+	Logger *zap.Logger
+
+	// Information about the document as stored in Paperless. Always set,
+	// though values may be empty outside of the cataloger.
+	Info *DocumentInfo
+
+	// Path to the local document file.
+	Path string
+
+	// Format of the document file as a MIME type, e.g. [FormatPDF]. Always
+	// [FormatPDF] unless the facter implements [FormatsFacter].
+	Format string
+
+	// Parsed PDF document. Nil for other formats.
 	Document *dossier.Document
+
+	// Text of documents in formats other than PDF, e.g. the content of
+	// a plain text file or the body of an e-mail.
+	Text string
+
+	// Header of an e-mail ([FormatMail]). Nil for other formats. Values may
+	// be encoded as per RFC 2047 (see [mime.WordDecoder]).
+	MailHeader mail.Header
+
+	// Barcodes decoded from the pages of the document, in page order. Only
+	// set when barcode scanning is enabled.
+	Barcodes []Barcode
+}
+
+// Barcode is a barcode or QR code found on a document page.
+type Barcode struct {
+	// Symbology, e.g. "QR_CODE" or "CODE_128".
+	Format string `json:"format"`
+
+	// Page number, starting at 1.
+	Page int `json:"page"`
+
+	// Decoded payload.
+	Text string `json:"text"`
 }
 
 type DocumentFacter interface {
 	staticplug.Plugin
 
-	// DocumentFacts is invoked after a document has been parsed into
-	// structured text. The return value can be nil to report that no suitable
-	// facts were found.
+	// DocumentFacts is invoked after a document file has been parsed. Only
+	// PDF documents are given to facters not implementing [FormatsFacter].
+	// The return value can be nil to report that no suitable facts were
+	// found.
 	DocumentFacts(context.Context, DocumentFacterOptions) (*Facts, error)
 }
+
+// FormatsFacter is implemented by document facters also accepting formats
+// other than PDF.
+type FormatsFacter interface {
+	DocumentFacter
+
+	// Formats returns the accepted formats as MIME types, e.g. [FormatPDF]
+	// and [FormatMail].
+	Formats() []string
+}
+
+// DocumentInfo describes a document as stored in Paperless. It must not be
+// modified.
+type DocumentInfo struct {
+	ID                  int64     `json:"id"`
+	Title               string    `json:"title"`
+	Created             time.Time `json:"created"`
+	Modified            time.Time `json:"modified"`
+	Added               time.Time `json:"added"`
+	ArchiveSerialNumber *int64    `json:"archive_serial_number"`
+	OriginalFileName    string    `json:"original_file_name"`
+	OriginalMimeType    string    `json:"original_mime_type"`
+
+	// Language detected by Paperless, if any.
+	Language string `json:"language"`
+
+	// Names of the objects currently assigned to the document. Empty if not
+	// assigned.
+	Tags          []string `json:"tags"`
+	Correspondent string   `json:"correspondent"`
+	DocumentType  string   `json:"document_type"`
+	StoragePath   string   `json:"storage_path"`
+
+	// Username of the document owner.
+	Owner string `json:"owner"`
+}
+
+type ContentFacterOptions struct {
+	Logger *zap.Logger
+	Info   *DocumentInfo
+
+	// Text content of the document as provided by Paperless, usually derived
+	// via OCR.
+	Content string
+}
+
+// ContentFacter is implemented by plugins needing only the text content of
+// a document. Downloading and parsing the document file is skipped when
+// content facters report facts.
+type ContentFacter interface {
+	staticplug.Plugin
+
+	// ContentFacts is invoked with the text content of a document. The return
+	// value can be nil to report that no suitable facts were found.
+	ContentFacts(context.Context, ContentFacterOptions) (*Facts, error)
+}